@@ -0,0 +1,72 @@
+package sqlmock
+
+import "fmt"
+
+// Expectation is implemented by all expectations, so that one may be
+// declared to happen after others, see the After method of expectations
+type Expectation interface {
+	expectation
+}
+
+// after declares the predecessors of an expectation, which must form a
+// directed acyclic graph of expectations of the same mock. A predecessor,
+// which would close a cycle, makes the expectation invalid.
+func (c *sqlmock) after(e expectation, preds []Expectation) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ce := e.common()
+	for _, pred := range preds {
+		var err error
+		switch {
+		case pred.common().mock != c:
+			err = fmt.Errorf("%s: %T may not happen after an expectation of another mock", ce.declared, e)
+		case pred == e || c.precedes(e, pred):
+			err = fmt.Errorf("%s: %T may not happen after the expectation declared at %s, which must happen after it", ce.declared, e, pred.common().declared)
+		default:
+			ce.after = append(ce.after, pred)
+			continue
+		}
+
+		e.Lock()
+		if ce.invalid == nil {
+			ce.invalid = err
+		}
+		e.Unlock()
+		if c.t != nil {
+			c.t.Error(err)
+		}
+	}
+}
+
+// precedes returns whether a must happen before b, c.mu must be held
+func (c *sqlmock) precedes(a, b expectation) bool {
+	for _, pred := range b.common().after {
+		if pred == a || c.precedes(a, pred) {
+			return true
+		}
+	}
+	return false
+}
+
+// predecessor returns the first predecessor of the expectation,
+// which was not triggered yet, c.mu must be held
+func (c *sqlmock) predecessor(e expectation) expectation {
+	for _, pred := range e.common().after {
+		if !pred.common().triggered {
+			return pred
+		}
+	}
+	return nil
+}
+
+// violation describes a call, which matches an expectation before its
+// predecessor was triggered, the expectation must be locked
+func (c *sqlmock) violation(e, pred expectation) error {
+	pred.Lock()
+	defer pred.Unlock()
+	return fmt.Errorf("it must happen after an expectation, which was not triggered yet:"+
+		"\n  - declared at %s, matched by call #%d: %s"+
+		"\n  - declared at %s, not triggered yet: %s",
+		e.common().declared, len(c.journal)+1, e, pred.common().declared, pred)
+}
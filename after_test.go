@@ -0,0 +1,92 @@
+package sqlmock
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAfterConstrainsUnorderedExpectations(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New(AllowRejectedCallsOption(true))
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+	mock.MatchExpectationsInOrder(false)
+
+	sel := mock.ExpectQuery("SELECT balance").WillReturnRows(NewRows([]string{"balance"}).AddRow(10))
+	mock.ExpectExec("UPDATE accounts").WillReturnResult(NewResult(0, 1)).After(sel)
+	mock.ExpectExec("INSERT INTO audit").WillReturnResult(NewResult(1, 1))
+
+	_, err = db.Exec("UPDATE accounts SET balance = 0")
+	if err == nil || !strings.Contains(err.Error(), "must happen after") {
+		t.Fatalf("expected the update to violate its order, but got: %v", err)
+	}
+	if _, err := db.Exec("INSERT INTO audit VALUES (1)"); err != nil {
+		t.Errorf("unexpected error on unconstrained insert: %s", err)
+	}
+	var balance int
+	if err := db.QueryRow("SELECT balance FROM accounts").Scan(&balance); err != nil {
+		t.Errorf("unexpected error on select: %s", err)
+	}
+	if _, err := db.Exec("UPDATE accounts SET balance = 0"); err != nil {
+		t.Errorf("unexpected error on update after the select: %s", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestAfterMatchesAnotherExpectation(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+	mock.MatchExpectationsInOrder(false)
+
+	begin := mock.ExpectBegin()
+	mock.ExpectExec("UPDATE jobs").WillReturnResult(NewResult(0, 2)).After(begin)
+	mock.ExpectExec("UPDATE jobs").WillReturnResult(NewResult(0, 1))
+	mock.ExpectCommit()
+
+	res, err := db.Exec("UPDATE jobs SET done = 1")
+	if err != nil {
+		t.Fatalf("unexpected error on update: %s", err)
+	}
+	if n, _ := res.RowsAffected(); n != 1 {
+		t.Errorf("expected the unconstrained update to be matched, but got %d rows affected", n)
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("unexpected error on begin: %s", err)
+	}
+	if _, err := tx.Exec("UPDATE jobs SET done = 1"); err != nil {
+		t.Errorf("unexpected error on update after begin: %s", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Errorf("unexpected error on commit: %s", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestAfterRejectsCycles(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	begin := mock.ExpectBegin()
+	commit := mock.ExpectCommit().After(begin)
+	begin.After(commit)
+
+	err = mock.ExpectationsWereMet()
+	if err == nil || !strings.Contains(err.Error(), "which must happen after it") {
+		t.Errorf("expected the cycle to be reported, but got: %v", err)
+	}
+}
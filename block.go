@@ -0,0 +1,40 @@
+package sqlmock
+
+// blocker holds a call, which matched the expectation, in flight
+// until it is released, see WillBlockUntil and Reached
+type blocker struct {
+	release <-chan struct{}
+	reached chan struct{}
+}
+
+// reachedChan returns the channel, which is closed once a call
+// matched the expectation, the expectation must be locked
+func (b *blocker) reachedChan() <-chan struct{} {
+	if b.reached == nil {
+		b.reached = make(chan struct{})
+	}
+	return b.reached
+}
+
+// reach closes the reached channel, the expectation must be locked
+func (b *blocker) reach() {
+	b.reachedChan()
+	select {
+	case <-b.reached:
+	default:
+		close(b.reached)
+	}
+}
+
+// rearm drops the reached channel, so that the next call matching
+// the expectation closes a new one, the expectation must be locked
+func (b *blocker) rearm() {
+	b.reached = nil
+}
+
+// block waits until the call is released
+func (c *sqlmock) block(release <-chan struct{}) {
+	if release != nil {
+		<-release
+	}
+}
@@ -0,0 +1,48 @@
+package sqlmock
+
+// CallKind identifies the kind of a database
+// driver call received by the mock
+type CallKind int
+
+// kinds of database driver calls
+const (
+	CallBegin CallKind = iota
+	CallPrepare
+	CallQuery
+	CallExec
+	CallCommit
+	CallRollback
+	CallPing
+	CallClose
+)
+
+// kinds of calls, which are matched against expectations,
+// but are not subject to latency and fault injection
+const (
+	callConnect CallKind = iota + CallClose + 1
+	callResetSession
+	callIsValid
+)
+
+var callKindNames = map[CallKind]string{
+	CallBegin:    "Begin",
+	CallPrepare:  "Prepare",
+	CallQuery:    "Query",
+	CallExec:     "Exec",
+	CallCommit:   "Commit",
+	CallRollback: "Rollback",
+	CallPing:     "Ping",
+	CallClose:    "Close",
+
+	callConnect:      "Connect",
+	callResetSession: "ResetSession",
+	callIsValid:      "IsValid",
+}
+
+// String returns the name of the call kind
+func (k CallKind) String() string {
+	if name, ok := callKindNames[k]; ok {
+		return name
+	}
+	return "Unknown"
+}
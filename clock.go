@@ -0,0 +1,191 @@
+package sqlmock
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock is the source of time used by sqlmock to delay
+// results of expectations with WillDelayFor and rows
+// with RowDelay. By default the wall clock is used,
+// a FakeClock may be passed through ClockOption in order
+// to control the time in tests.
+type Clock interface {
+	// Now returns the current time of the clock.
+	Now() time.Time
+
+	// NewTimer creates a Timer which fires once
+	// the given duration has passed on the clock.
+	NewTimer(d time.Duration) Timer
+}
+
+// Timer is created by Clock.NewTimer
+type Timer interface {
+	// C returns the channel on which the time is
+	// delivered when the timer fires.
+	C() <-chan time.Time
+
+	// Stop prevents the timer from firing.
+	Stop() bool
+}
+
+// callClock is implemented by clocks, which keep
+// track of the calls waiting on their timers
+type callClock interface {
+	newCallTimer(d time.Duration, call, sql string) Timer
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) NewTimer(d time.Duration) Timer {
+	return realTimer{time.NewTimer(d)}
+}
+
+type realTimer struct {
+	*time.Timer
+}
+
+func (t realTimer) C() <-chan time.Time {
+	return t.Timer.C
+}
+
+// FakeClock is a Clock which time moves only when it is
+// advanced manually, so delayed calls do not really sleep.
+// Tests may wait until a number of calls are blocked on the
+// clock with BlockUntil and release them with Advance.
+type FakeClock struct {
+	mu     sync.Mutex
+	cond   *sync.Cond
+	now    time.Time
+	timers []*fakeTimer
+}
+
+// NewFakeClock creates a FakeClock starting at the given time
+func NewFakeClock(now time.Time) *FakeClock {
+	c := &FakeClock{now: now}
+	c.cond = sync.NewCond(&c.mu)
+	return c
+}
+
+// Now returns the current virtual time
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// NewTimer creates a Timer which fires when the clock is
+// advanced by the given duration
+func (c *FakeClock) NewTimer(d time.Duration) Timer {
+	return c.newCallTimer(d, "", "")
+}
+
+// newCallTimer creates a Timer for the given call, which
+// is reported by Waiting until the timer fires
+func (c *FakeClock) newCallTimer(d time.Duration, call, sql string) Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t := &fakeTimer{clock: c, until: c.now.Add(d), ch: make(chan time.Time, 1), call: call, sql: sql}
+	if d <= 0 {
+		t.ch <- c.now
+		return t
+	}
+
+	c.timers = append(c.timers, t)
+	c.cond.Broadcast()
+	return t
+}
+
+// Advance moves the clock forward by the given duration and fires
+// all the timers which are due at the new time
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+	pending := c.timers[:0]
+	for _, t := range c.timers {
+		if t.until.After(c.now) {
+			pending = append(pending, t)
+			continue
+		}
+		t.ch <- c.now
+	}
+	c.timers = pending
+	c.cond.Broadcast()
+}
+
+// Waiters returns the number of timers which did
+// not fire yet, that is the number of calls blocked
+// on the clock at the current virtual time
+func (c *FakeClock) Waiters() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.timers)
+}
+
+// Waiter is a call blocked on a FakeClock, as returned by Waiting
+type Waiter struct {
+	// Call is the name of the delayed call, like Query or Rows.Next
+	Call string
+	// SQL is the statement of the delayed call, if it has one
+	SQL string
+	// Until is the virtual time at which the call is released
+	Until time.Time
+}
+
+// Waiting returns the calls blocked on the clock at the current
+// virtual time, in the order they started to wait. Timers created
+// by NewTimer, which the mock did not create, have no call.
+func (c *FakeClock) Waiting() []Waiter {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	waiters := make([]Waiter, len(c.timers))
+	for i, t := range c.timers {
+		waiters[i] = Waiter{Call: t.call, SQL: t.sql, Until: t.until}
+	}
+	return waiters
+}
+
+// BlockUntil blocks until there are at least n
+// calls waiting on the clock
+func (c *FakeClock) BlockUntil(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for len(c.timers) < n {
+		c.cond.Wait()
+	}
+}
+
+type fakeTimer struct {
+	clock *FakeClock
+	until time.Time
+	ch    chan time.Time
+	call  string
+	sql   string
+}
+
+func (t *fakeTimer) C() <-chan time.Time {
+	return t.ch
+}
+
+func (t *fakeTimer) Stop() bool {
+	c := t.clock
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i, pending := range c.timers {
+		if pending == t {
+			c.timers = append(c.timers[:i], c.timers[i+1:]...)
+			c.cond.Broadcast()
+			return true
+		}
+	}
+	return false
+}
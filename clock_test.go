@@ -0,0 +1,137 @@
+// +build go1.8
+
+package sqlmock
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestFakeClockAdvance(t *testing.T) {
+	t.Parallel()
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := NewFakeClock(start)
+
+	short := clock.NewTimer(time.Second)
+	long := clock.NewTimer(time.Minute)
+	if n := clock.Waiters(); n != 2 {
+		t.Fatalf("expected 2 waiters, but got %d", n)
+	}
+
+	clock.Advance(time.Second)
+	select {
+	case now := <-short.C():
+		if !now.Equal(start.Add(time.Second)) {
+			t.Errorf("unexpected time delivered: %s", now)
+		}
+	default:
+		t.Error("expected short timer to fire")
+	}
+
+	select {
+	case <-long.C():
+		t.Error("long timer was not expected to fire")
+	default:
+	}
+
+	if !long.Stop() {
+		t.Error("expected pending timer to be stopped")
+	}
+	if n := clock.Waiters(); n != 0 {
+		t.Errorf("expected no waiters, but got %d", n)
+	}
+	if !clock.Now().Equal(start.Add(time.Second)) {
+		t.Errorf("unexpected clock time: %s", clock.Now())
+	}
+}
+
+func TestClockOptionDelaysExec(t *testing.T) {
+	t.Parallel()
+	clock := NewFakeClock(time.Now())
+	db, mock, err := New(ClockOption(clock))
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectExec("UPDATE users").
+		WillDelayFor(time.Hour).
+		WillReturnResult(NewResult(0, 1))
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := db.ExecContext(context.Background(), "UPDATE users SET name = 'john'")
+		done <- err
+	}()
+
+	clock.BlockUntil(1)
+	waiting := clock.Waiting()
+	if len(waiting) != 1 || waiting[0].Call != "Exec" || waiting[0].SQL != "UPDATE users SET name = 'john'" {
+		t.Fatalf("expected the exec to be waiting on the clock, but got: %+v", waiting)
+	}
+	if until := clock.Now().Add(time.Hour); !waiting[0].Until.Equal(until) {
+		t.Errorf("expected the exec to wait until %s, but got: %s", until, waiting[0].Until)
+	}
+	clock.Advance(time.Minute)
+	select {
+	case <-done:
+		t.Fatal("exec was not expected to complete before the delay has passed")
+	default:
+	}
+
+	clock.Advance(time.Hour)
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("error was not expected, but got: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected exec to complete once the clock was advanced")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestClockOptionCancelReleasesWaiter(t *testing.T) {
+	t.Parallel()
+	clock := NewFakeClock(time.Now())
+	db, mock, err := New(ClockOption(clock))
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	rs := NewRows([]string{"id"}).AddRow(1).RowDelay(time.Hour)
+	mock.ExpectQuery("SELECT id FROM users").WillReturnRows(rs)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	rows, err := db.QueryContext(ctx, "SELECT id FROM users")
+	if err != nil {
+		t.Fatalf("error was not expected, but got: %v", err)
+	}
+	defer rows.Close()
+
+	done := make(chan bool, 1)
+	go func() {
+		done <- rows.Next()
+	}()
+
+	clock.BlockUntil(1)
+	cancel()
+
+	select {
+	case next := <-done:
+		if next {
+			t.Error("row was not expected after cancellation")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected rows.Next to return once the context was cancelled")
+	}
+
+	if n := clock.Waiters(); n != 0 {
+		t.Errorf("expected no waiters after cancellation, but got %d", n)
+	}
+}
@@ -0,0 +1,145 @@
+package sqlmock
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestConcurrentRegistrationAndMatching(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+	mock.MatchExpectationsInOrder(false)
+
+	const workers = 50
+	var wg sync.WaitGroup
+	errs := make(chan error, workers*2)
+	done := make(chan struct{})
+
+	// keep checking expectations while they are registered and matched
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			default:
+				_ = mock.ExpectationsWereMet()
+			}
+		}
+	}()
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			// each worker uses its own table, so a half built expectation
+			// of one worker is never matched by the calls of another
+			mock.ExpectExec(fmt.Sprintf("UPDATE users_%d SET", i)).WithArgs(i).WillReturnResult(NewResult(0, 1))
+			if _, err := db.Exec(fmt.Sprintf("UPDATE users_%d SET visits = visits + 1 WHERE id = ?", i), i); err != nil {
+				errs <- fmt.Errorf("exec %d: %s", i, err)
+			}
+
+			mock.ExpectQuery(fmt.Sprintf("SELECT name FROM users_%d WHERE", i)).WithArgs(i).WillReturnRows(NewRows([]string{"name"}).AddRow("john"))
+			rows, err := db.Query(fmt.Sprintf("SELECT name FROM users_%d WHERE id = ?", i), i)
+			if err != nil {
+				errs <- fmt.Errorf("query %d: %s", i, err)
+				return
+			}
+			rows.Close()
+		}(i)
+	}
+	wg.Wait()
+	close(done)
+	close(errs)
+
+	for err := range errs {
+		t.Error(err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestConcurrentPreparedRegistration(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+	mock.MatchExpectationsInOrder(false)
+
+	const workers = 20
+	prepared := make([]*ExpectedPrepare, workers)
+	for i := range prepared {
+		prepared[i] = mock.ExpectPrepare(fmt.Sprintf("INSERT INTO audit_%d VALUES", i))
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, workers)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			prepared[i].ExpectExec().WillReturnResult(NewResult(1, 1))
+			stmt, err := db.Prepare(fmt.Sprintf("INSERT INTO audit_%d VALUES (1)", i))
+			if err != nil {
+				errs <- fmt.Errorf("prepare %d: %s", i, err)
+				return
+			}
+			defer stmt.Close()
+			if _, err := stmt.Exec(); err != nil {
+				errs <- fmt.Errorf("exec %d: %s", i, err)
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Error(err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestConcurrentSettingsAndPings(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New(MonitorPingsOption(true), AllowRejectedCallsOption(true))
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	const workers = 20
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			mock.MatchExpectationsInOrder(false)
+			mock.ExpectPing()
+		}()
+		go func() {
+			defer wg.Done()
+			_ = db.Ping()
+		}()
+	}
+	wg.Wait()
+
+	// pings which raced ahead of their expectation are matched now,
+	// at most one for each expected ping
+	for i := 0; i < workers && mock.ExpectationsWereMet() != nil; i++ {
+		if err := db.Ping(); err != nil {
+			t.Fatalf("unexpected error on ping: %s", err)
+		}
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
@@ -0,0 +1,165 @@
+package sqlmock
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+)
+
+// conn is a connection opened to a mock. All connections to a mock
+// share its expectations, but each one may be broken on its own by
+// an expectation with WillBreakConnection.
+type conn struct {
+	*sqlmock
+	broken    bool     // guarded by the mutex of the mock
+	discarded bool     // guarded by the mutex of the mock
+	tx        *txState // the open transaction, guarded by the mutex of the mock
+}
+
+// txState is the state of a transaction begun on a connection, it
+// is open until the first call to Commit or Rollback on it
+type txState struct {
+	begun string // describes the Begin call, which opened it
+	done  bool
+}
+
+// Prepare is kept on the mock for callers, which use it as a connection
+func (c *sqlmock) Prepare(query string) (driver.Stmt, error) {
+	return (&conn{sqlmock: c}).Prepare(query)
+}
+
+// Query is kept on the mock for callers, which use it as a connection
+func (c *sqlmock) Query(query string, args []driver.Value) (driver.Rows, error) {
+	return (&conn{sqlmock: c}).Query(query, args)
+}
+
+// Exec is kept on the mock for callers, which use it as a connection
+func (c *sqlmock) Exec(query string, args []driver.Value) (driver.Result, error) {
+	return (&conn{sqlmock: c}).Exec(query, args)
+}
+
+// isBroken returns whether an expectation broke the connection
+func (c *conn) isBroken() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.broken
+}
+
+// breakIf breaks the connection, when the expectation matched by
+// the call on it breaks connections
+func (c *conn) breakIf(breaks bool) {
+	if !breaks {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.broken = true
+}
+
+// discard marks that the connection was reported as bad, so
+// database/sql is going to discard it
+func (c *conn) discard() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.discarded = true
+}
+
+// discardIf discards the connection, when a call on it returned
+// driver.ErrBadConn, since database/sql closes it and retries the
+// call on another connection
+func (c *conn) discardIf(err error) {
+	if isBadConn(err) {
+		c.discard()
+	}
+}
+
+// isDiscarded returns whether database/sql discards the connection,
+// so closing it is not the database being closed
+func (c *conn) isDiscarded() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.broken || c.discarded
+}
+
+// checkBegin rejects beginning a transaction on a connection,
+// which has an open one
+func (c *conn) checkBegin() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.tx == nil {
+		return nil
+	}
+	err := fmt.Errorf("call to database transaction Begin, the connection already has an open transaction %s", c.tx.begun)
+	if c.t != nil {
+		c.t.Error(err)
+	}
+	return c.reject(CallBegin, "", nil, err)
+}
+
+// open opens a transaction on the connection, once the Begin
+// call matching the expectation succeeded
+func (c *conn) open(ex *ExpectedBegin) *txState {
+	tx := &txState{begun: "begun by an ignored call"}
+	if ex.declared != "" {
+		tx.begun = "begun by the call matching the expectation declared at " + ex.declared
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.tx = tx
+	c.txs = append(c.txs, tx)
+	return tx
+}
+
+// finish commits or rolls back the transaction, which is nil for
+// the transaction open on the connection, since Begin returns the
+// connection itself. The transaction is done afterwards, whatever
+// the call returns, like database/sql treats it.
+func (c *conn) finish(kind CallKind, tx *txState) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if tx == nil {
+		tx = c.tx
+	}
+	var err error
+	switch {
+	case tx == nil:
+		err = fmt.Errorf("call to %s transaction, there is no transaction open on the connection", kind)
+	case tx.done:
+		err = txDoneError(fmt.Sprintf("call to %s transaction", kind))
+	}
+	if err != nil {
+		if c.t != nil {
+			c.t.Error(err)
+		}
+		return c.reject(kind, "", nil, err)
+	}
+
+	tx.done = true
+	if c.tx == tx {
+		c.tx = nil
+	}
+	for i, open := range c.txs {
+		if open == tx {
+			c.txs = append(c.txs[:i], c.txs[i+1:]...)
+			break
+		}
+	}
+	c.notify()
+	return nil
+}
+
+// leaked returns an error listing the transactions, which were
+// neither committed nor rolled back, c.mu must be held
+func (c *sqlmock) leaked() error {
+	if len(c.txs) == 0 {
+		return nil
+	}
+	msg := fmt.Sprintf("there are %d transactions, which were neither committed nor rolled back:", len(c.txs))
+	for _, tx := range c.txs {
+		msg += "\n  - " + tx.begun
+	}
+	return errors.New(msg)
+}
@@ -0,0 +1,21 @@
+// +build !go1.13
+
+package sqlmock
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+)
+
+// isBadConn returns whether the error makes database/sql
+// discard the connection, errors are not wrapped before Go 1.13
+func isBadConn(err error) bool {
+	return err == driver.ErrBadConn
+}
+
+// txDoneError returns the error of the described call,
+// which is made in a transaction done already
+func txDoneError(call string) error {
+	return fmt.Errorf("%s, %s", call, sql.ErrTxDone)
+}
@@ -0,0 +1,22 @@
+// +build go1.13
+
+package sqlmock
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+)
+
+// isBadConn returns whether the error makes database/sql discard
+// the connection, errors wrapping driver.ErrBadConn included
+func isBadConn(err error) bool {
+	return errors.Is(err, driver.ErrBadConn)
+}
+
+// txDoneError returns the error of the described call, which is
+// made in a transaction done already, it wraps sql.ErrTxDone
+func txDoneError(call string) error {
+	return fmt.Errorf("%s, %w", call, sql.ErrTxDone)
+}
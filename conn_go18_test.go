@@ -0,0 +1,333 @@
+// +build go1.8
+
+package sqlmock
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestWillBreakConnectionFailsLaterCallsInTransaction(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO orders").WillReturnResult(NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO items").WillReturnResult(NewResult(1, 1)).WillBreakConnection()
+	mock.ExpectQuery("SELECT id FROM orders").WillReturnRows(NewRows([]string{"id"}).AddRow(1))
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when beginning a transaction", err)
+	}
+	if _, err := tx.Exec("INSERT INTO orders (id) VALUES (1)"); err != nil {
+		t.Fatalf("an error '%s' was not expected on the first exec", err)
+	}
+	if _, err := tx.Exec("INSERT INTO items (order_id) VALUES (1)"); err != nil {
+		t.Fatalf("the call breaking the connection should get its response, but got: %s", err)
+	}
+	if _, err := tx.Exec("INSERT INTO items (order_id) VALUES (1)"); !errors.Is(err, driver.ErrBadConn) {
+		t.Fatalf("expected driver.ErrBadConn on the broken connection, but got: %v", err)
+	}
+	if err := tx.Rollback(); !errors.Is(err, driver.ErrBadConn) {
+		t.Fatalf("expected driver.ErrBadConn on rollback, but got: %v", err)
+	}
+
+	rows, err := db.Query("SELECT id FROM orders")
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected on a new connection", err)
+	}
+	rows.Close()
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestWillBreakConnectionReportsConnectionInvalid(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectExec("UPDATE users").WillReturnResult(NewResult(0, 1)).WillBreakConnection()
+	mock.ExpectExec("UPDATE users").WillReturnResult(NewResult(0, 1))
+	mock.ExpectClose()
+
+	if _, err := db.Exec("UPDATE users SET name = 'john'"); err != nil {
+		t.Fatalf("error was not expected, but got: %v", err)
+	}
+
+	if n := db.Stats().OpenConnections; n != 0 {
+		t.Errorf("expected broken connection to be discarded, but there are %d open connections", n)
+	}
+
+	if _, err := db.Exec("UPDATE users SET name = 'jane'"); err != nil {
+		t.Fatalf("error was not expected on a new connection, but got: %v", err)
+	}
+
+	if err := db.Close(); err != nil {
+		t.Fatalf("error was not expected on close, but got: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestExpectationsWereMetReportsLeakedTransactions(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when beginning a transaction", err)
+	}
+
+	err = mock.ExpectationsWereMet()
+	if err == nil || !strings.Contains(err.Error(), "there are 1 transactions, which were neither committed nor rolled back") {
+		t.Fatalf("expected the open transaction to be reported, but got: %v", err)
+	}
+	if !strings.Contains(err.Error(), "conn_go18_test.go") {
+		t.Errorf("expected the declaration of the begin expectation to be reported, but got: %s", err)
+	}
+
+	mock.ExpectCommit()
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("an error '%s' was not expected when committing a transaction", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestTransactionStateIsValidated(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New(AllowRejectedCallsOption(true))
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+	mock.MatchExpectationsInOrder(false)
+
+	mock.ExpectCommit()
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+
+	dc, err := db.Driver().Open(mock.(*sqlmock).dsn)
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a connection", err)
+	}
+	defer dc.Close()
+	cn := dc.(*conn)
+
+	if err := cn.Commit(); err == nil || !strings.Contains(err.Error(), "no transaction open") {
+		t.Errorf("expected commit without begin to be rejected, but got: %v", err)
+	}
+
+	tx, err := cn.BeginTx(context.Background(), driver.TxOptions{})
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when beginning a transaction", err)
+	}
+	if _, err := cn.BeginTx(context.Background(), driver.TxOptions{}); err == nil {
+		t.Error("expected begin on a connection with an open transaction to be rejected")
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("an error '%s' was not expected when committing a transaction", err)
+	}
+	if err := tx.Commit(); !errors.Is(err, sql.ErrTxDone) {
+		t.Errorf("expected sql.ErrTxDone on a second commit, but got: %v", err)
+	}
+	if err := tx.Rollback(); !errors.Is(err, sql.ErrTxDone) {
+		t.Errorf("expected sql.ErrTxDone on rollback after commit, but got: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err == nil {
+		t.Error("expected the commit without begin to be left unfulfilled")
+	}
+
+	rejected := 0
+	for _, call := range mock.Calls() {
+		if call.Outcome == CallRejected {
+			rejected++
+		}
+	}
+	if rejected != 4 {
+		t.Errorf("expected 4 rejected calls, but got %d", rejected)
+	}
+}
+
+func TestBrokenLastConnectionReleasesDSN(t *testing.T) {
+	t.Parallel()
+	db, mock, err := NewWithDSN("sqlmock_broken_last_connection")
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	mock.ExpectExec("UPDATE users").WillReturnResult(NewResult(0, 1)).WillBreakConnection()
+	if closesConnector {
+		// the database is closed, even though its connection was broken
+		mock.ExpectClose()
+	}
+	if _, err := db.Exec("UPDATE users SET name = 'john'"); err != nil {
+		t.Fatalf("error was not expected, but got: %v", err)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatalf("error was not expected on close, but got: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+
+	db, _, err = NewWithDSN("sqlmock_broken_last_connection")
+	if err != nil {
+		t.Fatalf("expected the dsn to be released with its last connection, but got: %s", err)
+	}
+	db.Close()
+}
+
+func TestBadConnectionIsNotClosingTheDatabase(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectExec("UPDATE users").WillReturnError(driver.ErrBadConn)
+	mock.ExpectExec("UPDATE users").WillReturnResult(NewResult(0, 1))
+
+	if _, err := db.Exec("UPDATE users SET name = 'john'"); err != nil {
+		t.Fatalf("expected the call to be retried on a new connection, but got: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+	for _, call := range mock.Calls() {
+		if call.Kind == CallClose {
+			t.Errorf("expected the bad connection not to be recorded as closing the database, but got: %+v", call)
+		}
+	}
+}
+
+func TestConnectionResetIsRetried(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectExec("UPDATE users").WillReturnError(DialectMySQL.NewError(ConnectionReset))
+	mock.ExpectExec("UPDATE users").WillReturnResult(NewResult(0, 1))
+
+	if _, err := db.Exec("UPDATE users SET name = 'john'"); err != nil {
+		t.Fatalf("expected the reset connection to be discarded and the call retried, but got: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestStatementOfFinishedTransactionIsRejected(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New(AllowRejectedCallsOption(true))
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	prep := mock.ExpectPrepare("UPDATE users")
+	prep.ExpectExec().WillReturnResult(NewResult(0, 1))
+	mock.ExpectCommit()
+
+	dc, err := db.Driver().Open(mock.(*sqlmock).dsn)
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a connection", err)
+	}
+	defer dc.Close()
+	cn := dc.(*conn)
+
+	tx, err := cn.BeginTx(context.Background(), driver.TxOptions{})
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when beginning a transaction", err)
+	}
+	stmt, err := cn.PrepareContext(context.Background(), "UPDATE users SET name = 'john'")
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when preparing a statement", err)
+	}
+	exec := stmt.(driver.StmtExecContext)
+	if _, err := exec.ExecContext(context.Background(), nil); err != nil {
+		t.Fatalf("an error '%s' was not expected when executing in the transaction", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("an error '%s' was not expected when committing a transaction", err)
+	}
+
+	if _, err := exec.ExecContext(context.Background(), nil); !errors.Is(err, sql.ErrTxDone) {
+		t.Errorf("expected sql.ErrTxDone on exec after commit, but got: %v", err)
+	}
+	if _, err := stmt.(driver.StmtQueryContext).QueryContext(context.Background(), nil); !errors.Is(err, sql.ErrTxDone) {
+		t.Errorf("expected sql.ErrTxDone on query after commit, but got: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+	rejected := 0
+	for _, call := range mock.Calls() {
+		if call.Outcome == CallRejected {
+			rejected++
+		}
+	}
+	if rejected != 2 {
+		t.Errorf("expected 2 rejected calls, but got %d", rejected)
+	}
+}
+
+func TestPoolClosingConnectionsIsNotClosingTheDatabase(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	db.SetMaxIdleConns(0)
+
+	mock.ExpectExec("UPDATE users").WillReturnResult(NewResult(0, 1))
+	mock.ExpectExec("UPDATE users").WillReturnResult(NewResult(0, 1))
+	mock.ExpectClose()
+
+	for i := 0; i < 2; i++ {
+		if _, err := db.Exec("UPDATE users SET name = 'john'"); err != nil {
+			t.Fatalf("error was not expected, but got: %v", err)
+		}
+	}
+	if err := db.Close(); err != nil {
+		t.Fatalf("error was not expected on close, but got: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+	for _, call := range mock.Calls() {
+		if call.Outcome == CallRejected {
+			t.Errorf("expected the connections closed by the pool not to be rejected, but got: %+v", call)
+		}
+	}
+}
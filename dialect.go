@@ -0,0 +1,85 @@
+package sqlmock
+
+import "fmt"
+
+// Dialect describes the SQL flavour of the mocked database.
+// When known, it is used to count the placeholders of SQL
+// statements, so prepared statements report the number of
+// arguments they take, like a real driver does.
+type Dialect string
+
+// supported dialects
+const (
+	DialectPostgres  Dialect = "postgres"
+	DialectMySQL     Dialect = "mysql"
+	DialectSQLite    Dialect = "sqlite"
+	DialectSQLServer Dialect = "sqlserver"
+)
+
+func parseDialect(name string) (Dialect, error) {
+	switch d := Dialect(name); d {
+	case DialectPostgres, DialectMySQL, DialectSQLite, DialectSQLServer:
+		return d, nil
+	}
+	return "", fmt.Errorf("unknown dialect: %s", name)
+}
+
+// placeholders returns the number of arguments the query takes,
+// placeholders in quoted strings and identifiers are skipped.
+// It returns -1 if the dialect is not known.
+func (d Dialect) placeholders(query string) int {
+	if _, err := parseDialect(string(d)); err != nil {
+		return -1
+	}
+
+	var positional, numbered int
+	var quote byte
+	for i := 0; i < len(query); i++ {
+		ch := query[i]
+		if quote != 0 {
+			if ch == quote {
+				quote = 0
+			}
+			continue
+		}
+
+		switch {
+		case ch == '\'' || ch == '"' || ch == '`':
+			quote = ch
+		case ch == '?' && (d == DialectMySQL || d == DialectSQLite):
+			positional++
+		case ch == '$' && (d == DialectPostgres || d == DialectSQLite):
+			if n, size := leadingNumber(query[i+1:]); size > 0 {
+				if n > numbered {
+					numbered = n
+				}
+				i += size
+			}
+		case ch == '@' && d == DialectSQLServer && i+1 < len(query) && (query[i+1] == 'p' || query[i+1] == 'P'):
+			if n, size := leadingNumber(query[i+2:]); size > 0 {
+				if n > numbered {
+					numbered = n
+				}
+				i += size + 1
+			}
+		}
+	}
+
+	switch d {
+	case DialectPostgres, DialectSQLServer:
+		return numbered
+	case DialectMySQL:
+		return positional
+	}
+	return positional + numbered
+}
+
+// leadingNumber parses the decimal number s starts with,
+// it returns the number and how many bytes it takes
+func leadingNumber(s string) (n, size int) {
+	for size < len(s) && s[size] >= '0' && s[size] <= '9' {
+		n = n*10 + int(s[size]-'0')
+		size++
+	}
+	return n, size
+}
@@ -0,0 +1,307 @@
+package sqlmock
+
+import (
+	"database/sql/driver"
+	"fmt"
+)
+
+// ErrorCondition is a common condition, which database drivers report
+// with an error of their own, see Dialect.NewError
+type ErrorCondition int
+
+// common error conditions, a ConnectionReset is not reported by the
+// server but by the driver, as driver.ErrBadConn, so that database/sql
+// discards the connection and retries the call on another one
+const (
+	UniqueViolation ErrorCondition = iota
+	ForeignKeyViolation
+	Deadlock
+	SerializationFailure
+	LockTimeout
+	ConnectionReset
+	ReadOnlyTransaction
+	StatementTimeout
+)
+
+var errorConditionNames = map[ErrorCondition]string{
+	UniqueViolation:      "unique violation",
+	ForeignKeyViolation:  "foreign key violation",
+	Deadlock:             "deadlock",
+	SerializationFailure: "serialization failure",
+	LockTimeout:          "lock timeout",
+	ConnectionReset:      "connection reset",
+	ReadOnlyTransaction:  "read-only transaction",
+	StatementTimeout:     "statement timeout",
+}
+
+// String returns the name of the condition
+func (c ErrorCondition) String() string {
+	if name, ok := errorConditionNames[c]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+// NewError returns the error the driver of the dialect reports for the
+// condition, shaped like the error of lib/pq, go-sql-driver/mysql,
+// mattn/go-sqlite3 or go-mssqldb respectively, to be used with
+// WillReturnError. It returns driver.ErrBadConn for a ConnectionReset
+// and nil for an unknown dialect or condition.
+func (d Dialect) NewError(c ErrorCondition) error {
+	if _, err := parseDialect(string(d)); err == nil && c == ConnectionReset {
+		return driver.ErrBadConn
+	}
+
+	switch d {
+	case DialectPostgres:
+		if err := NewPostgresError(c); err != nil {
+			return err
+		}
+	case DialectMySQL:
+		if err := NewMySQLError(c); err != nil {
+			return err
+		}
+	case DialectSQLite:
+		if err := NewSQLiteError(c); err != nil {
+			return err
+		}
+	case DialectSQLServer:
+		if err := NewSQLServerError(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PostgresError mirrors the fields of the error of lib/pq, which
+// carries the SQLSTATE code reported by the server
+type PostgresError struct {
+	Severity   string
+	Code       string
+	Message    string
+	Detail     string
+	Hint       string
+	Table      string
+	Constraint string
+}
+
+// Error returns the message like lib/pq does
+func (e *PostgresError) Error() string {
+	return "pq: " + e.Message
+}
+
+// SQLState returns the SQLSTATE code of the error
+func (e *PostgresError) SQLState() string {
+	return e.Code
+}
+
+var postgresErrors = map[ErrorCondition]PostgresError{
+	UniqueViolation: {
+		Code:       "23505",
+		Message:    `duplicate key value violates unique constraint "users_email_key"`,
+		Detail:     "Key (email)=(john@example.com) already exists.",
+		Table:      "users",
+		Constraint: "users_email_key",
+	},
+	ForeignKeyViolation: {
+		Code:       "23503",
+		Message:    `insert or update on table "orders" violates foreign key constraint "orders_user_id_fkey"`,
+		Detail:     `Key (user_id)=(1) is not present in table "users".`,
+		Table:      "orders",
+		Constraint: "orders_user_id_fkey",
+	},
+	Deadlock: {
+		Code:    "40P01",
+		Message: "deadlock detected",
+		Detail:  "Process 1 waits for ShareLock on transaction 2; blocked by process 2.",
+		Hint:    "See server log for query details.",
+	},
+	SerializationFailure: {
+		Code:    "40001",
+		Message: "could not serialize access due to concurrent update",
+	},
+	LockTimeout: {
+		Code:    "55P03",
+		Message: "canceling statement due to lock timeout",
+	},
+	ReadOnlyTransaction: {
+		Code:    "25006",
+		Message: "cannot execute INSERT in a read-only transaction",
+	},
+	StatementTimeout: {
+		Code:    "57014",
+		Message: "canceling statement due to statement timeout",
+	},
+}
+
+// NewPostgresError returns the error lib/pq reports for the condition,
+// the names of tables and constraints may be changed on the result.
+// It returns nil for an unknown condition and for a ConnectionReset,
+// which is not an error of the server.
+func NewPostgresError(c ErrorCondition) *PostgresError {
+	e, ok := postgresErrors[c]
+	if !ok {
+		return nil
+	}
+	if e.Severity == "" {
+		e.Severity = "ERROR"
+	}
+	return &e
+}
+
+// MySQLError mirrors the error of go-sql-driver/mysql, which
+// carries the error number and SQLSTATE reported by the server
+type MySQLError struct {
+	Number   uint16
+	SQLState [5]byte
+	Message  string
+}
+
+// Error returns the message like go-sql-driver/mysql does
+func (e *MySQLError) Error() string {
+	if e.SQLState != [5]byte{} {
+		return fmt.Sprintf("Error %d (%s): %s", e.Number, e.SQLState[:], e.Message)
+	}
+	return fmt.Sprintf("Error %d: %s", e.Number, e.Message)
+}
+
+var mysqlErrors = map[ErrorCondition]MySQLError{
+	UniqueViolation: {
+		Number:   1062,
+		SQLState: [5]byte{'2', '3', '0', '0', '0'},
+		Message:  "Duplicate entry 'john@example.com' for key 'users.email'",
+	},
+	ForeignKeyViolation: {
+		Number:   1452,
+		SQLState: [5]byte{'2', '3', '0', '0', '0'},
+		Message:  "Cannot add or update a child row: a foreign key constraint fails (`app`.`orders`, CONSTRAINT `orders_user_id_fk` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`))",
+	},
+	Deadlock: {
+		Number:   1213,
+		SQLState: [5]byte{'4', '0', '0', '0', '1'},
+		Message:  "Deadlock found when trying to get lock; try restarting transaction",
+	},
+	// InnoDB reports serialization conflicts as deadlocks
+	SerializationFailure: {
+		Number:   1213,
+		SQLState: [5]byte{'4', '0', '0', '0', '1'},
+		Message:  "Deadlock found when trying to get lock; try restarting transaction",
+	},
+	LockTimeout: {
+		Number:   1205,
+		SQLState: [5]byte{'H', 'Y', '0', '0', '0'},
+		Message:  "Lock wait timeout exceeded; try restarting transaction",
+	},
+	ReadOnlyTransaction: {
+		Number:   1792,
+		SQLState: [5]byte{'2', '5', '0', '0', '6'},
+		Message:  "Cannot execute statement in a READ ONLY transaction.",
+	},
+	StatementTimeout: {
+		Number:   3024,
+		SQLState: [5]byte{'H', 'Y', '0', '0', '0'},
+		Message:  "Query execution was interrupted, maximum statement execution time exceeded",
+	},
+}
+
+// NewMySQLError returns the error go-sql-driver/mysql reports for the
+// condition, it returns nil for an unknown condition and for a
+// ConnectionReset, which is not an error of the server
+func NewMySQLError(c ErrorCondition) *MySQLError {
+	e, ok := mysqlErrors[c]
+	if !ok {
+		return nil
+	}
+	return &e
+}
+
+// SQLiteError mirrors the error of mattn/go-sqlite3, which carries
+// the primary and extended result codes of SQLite
+type SQLiteError struct {
+	Code         int
+	ExtendedCode int
+	Message      string
+}
+
+// Error returns the message like mattn/go-sqlite3 does
+func (e *SQLiteError) Error() string {
+	return e.Message
+}
+
+// SQLite is embedded, so timeouts show as an interrupted statement
+var sqliteErrors = map[ErrorCondition]SQLiteError{
+	UniqueViolation:      {Code: 19, ExtendedCode: 2067, Message: "UNIQUE constraint failed: users.email"},
+	ForeignKeyViolation:  {Code: 19, ExtendedCode: 787, Message: "FOREIGN KEY constraint failed"},
+	Deadlock:             {Code: 6, ExtendedCode: 6, Message: "database table is locked"},
+	SerializationFailure: {Code: 5, ExtendedCode: 517, Message: "database is locked"},
+	LockTimeout:          {Code: 5, ExtendedCode: 5, Message: "database is locked"},
+	ReadOnlyTransaction:  {Code: 8, ExtendedCode: 8, Message: "attempt to write a readonly database"},
+	StatementTimeout:     {Code: 9, ExtendedCode: 9, Message: "interrupted"},
+}
+
+// NewSQLiteError returns the error mattn/go-sqlite3 reports for the
+// condition, it returns nil for an unknown condition and for a
+// ConnectionReset, which SQLite has no connection to suffer
+func NewSQLiteError(c ErrorCondition) *SQLiteError {
+	e, ok := sqliteErrors[c]
+	if !ok {
+		return nil
+	}
+	return &e
+}
+
+// SQLServerError mirrors the error of go-mssqldb, which carries
+// the error number, state and severity class reported by the server
+type SQLServerError struct {
+	Number  int32
+	State   uint8
+	Class   uint8
+	Message string
+}
+
+// Error returns the message like go-mssqldb does
+func (e *SQLServerError) Error() string {
+	return "mssql: " + e.Message
+}
+
+var sqlserverErrors = map[ErrorCondition]SQLServerError{
+	UniqueViolation: {
+		Number: 2627, State: 1, Class: 14,
+		Message: "Violation of UNIQUE KEY constraint 'UQ_users_email'. Cannot insert duplicate key in object 'dbo.users'. The duplicate key value is (john@example.com).",
+	},
+	ForeignKeyViolation: {
+		Number: 547, State: 0, Class: 16,
+		Message: `The INSERT statement conflicted with the FOREIGN KEY constraint "FK_orders_users". The conflict occurred in database "app", table "dbo.users", column 'id'.`,
+	},
+	Deadlock: {
+		Number: 1205, State: 51, Class: 13,
+		Message: "Transaction (Process ID 52) was deadlocked on lock resources with another process and has been chosen as the deadlock victim. Rerun the transaction.",
+	},
+	SerializationFailure: {
+		Number: 3960, State: 2, Class: 16,
+		Message: "Snapshot isolation transaction aborted due to update conflict. You cannot use snapshot isolation to access table 'dbo.users' directly or indirectly in database 'app' to update, delete, or insert the row that has been modified or deleted by another transaction. Retry the transaction or change the isolation level for the update/delete statement.",
+	},
+	LockTimeout: {
+		Number: 1222, State: 45, Class: 16,
+		Message: "Lock request time out period exceeded.",
+	},
+	ReadOnlyTransaction: {
+		Number: 3906, State: 1, Class: 16,
+		Message: `Failed to update database "app" because the database is read-only.`,
+	},
+	StatementTimeout: {
+		Number: 3617, State: 0, Class: 25,
+		Message: "Query timeout expired",
+	},
+}
+
+// NewSQLServerError returns the error go-mssqldb reports for the
+// condition, it returns nil for an unknown condition and for a
+// ConnectionReset, which is not an error of the server
+func NewSQLServerError(c ErrorCondition) *SQLServerError {
+	e, ok := sqlserverErrors[c]
+	if !ok {
+		return nil
+	}
+	return &e
+}
@@ -0,0 +1,85 @@
+package sqlmock
+
+import (
+	"database/sql/driver"
+	"testing"
+)
+
+func TestDialectErrorsCoverAllConditions(t *testing.T) {
+	t.Parallel()
+	dialects := []Dialect{DialectPostgres, DialectMySQL, DialectSQLite, DialectSQLServer}
+	for c := UniqueViolation; c <= StatementTimeout; c++ {
+		for _, d := range dialects {
+			err := d.NewError(c)
+			if err == nil {
+				t.Errorf("expected an error of %s dialect for %s", d, c)
+				continue
+			}
+			if err.Error() == "" {
+				t.Errorf("expected a message of %s dialect for %s", d, c)
+			}
+		}
+	}
+	if err := Dialect("oracle").NewError(Deadlock); err != nil {
+		t.Errorf("expected no error for an unknown dialect, but got: %s", err)
+	}
+	if err := DialectPostgres.NewError(ErrorCondition(-1)); err != nil {
+		t.Errorf("expected no error for an unknown condition, but got: %s", err)
+	}
+}
+
+func TestDialectErrorShapes(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		err  error
+		want string
+	}{
+		{NewPostgresError(UniqueViolation), `pq: duplicate key value violates unique constraint "users_email_key"`},
+		{NewMySQLError(Deadlock), "Error 1213 (40001): Deadlock found when trying to get lock; try restarting transaction"},
+		{NewSQLiteError(UniqueViolation), "UNIQUE constraint failed: users.email"},
+		{NewSQLServerError(LockTimeout), "mssql: Lock request time out period exceeded."},
+	}
+	for _, c := range cases {
+		if c.err.Error() != c.want {
+			t.Errorf("expected error message %q, but got: %q", c.want, c.err.Error())
+		}
+	}
+
+	if code := NewPostgresError(SerializationFailure).SQLState(); code != "40001" {
+		t.Errorf("expected SQLSTATE 40001 for a serialization failure, but got: %s", code)
+	}
+	if e := NewSQLiteError(ForeignKeyViolation); e.Code != 19 || e.ExtendedCode != 787 {
+		t.Errorf("expected SQLITE_CONSTRAINT_FOREIGNKEY, but got: %d/%d", e.Code, e.ExtendedCode)
+	}
+	if e := NewMySQLError(SerializationFailure); e.Number != 1213 || string(e.SQLState[:]) != "40001" {
+		t.Errorf("expected InnoDB to report a serialization failure as 1213 (40001), but got: %s", e)
+	}
+	for _, d := range []Dialect{DialectPostgres, DialectMySQL, DialectSQLite, DialectSQLServer} {
+		if err := d.NewError(ConnectionReset); err != driver.ErrBadConn {
+			t.Errorf("expected driver.ErrBadConn for a connection reset of %s dialect, but got: %v", d, err)
+		}
+	}
+}
+
+func TestDialectErrorWithWillReturnError(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectExec("INSERT INTO users").WillReturnError(DialectPostgres.NewError(UniqueViolation))
+
+	_, err = db.Exec("INSERT INTO users (email) VALUES ('john@example.com')")
+	pqErr, ok := err.(*PostgresError)
+	if !ok {
+		t.Fatalf("expected a postgres error, but got: %v", err)
+	}
+	if pqErr.Code != "23505" || pqErr.Constraint != "users_email_key" {
+		t.Errorf("expected a unique violation of users_email_key, but got: %+v", pqErr)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
@@ -0,0 +1,55 @@
+package sqlmock
+
+import "testing"
+
+func TestDialectPlaceholders(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		dialect  Dialect
+		query    string
+		expected int
+	}{
+		{"", "SELECT * FROM users WHERE id = ?", -1},
+		{DialectMySQL, "SELECT * FROM users WHERE id = ? AND name = ?", 2},
+		{DialectMySQL, "SELECT * FROM users WHERE name = '?' AND `?` = ?", 1},
+		{DialectPostgres, "SELECT * FROM users WHERE id = $1 OR parent = $1 AND name = $2", 2},
+		{DialectPostgres, "SELECT '$3' FROM users WHERE id = $1", 1},
+		{DialectPostgres, "SELECT * FROM users WHERE id = ?", 0},
+		{DialectSQLite, "SELECT * FROM users WHERE id = ? AND name = ?", 2},
+		{DialectSQLite, "SELECT * FROM users WHERE id = $1", 1},
+		{DialectSQLServer, "SELECT * FROM users WHERE id = @p1 AND name = @P2", 2},
+		{DialectSQLServer, "SELECT @@VERSION", 0},
+	}
+	for _, c := range cases {
+		if n := c.dialect.placeholders(c.query); n != c.expected {
+			t.Errorf("expected %d placeholders for %q in %q dialect, but got %d", c.expected, c.query, c.dialect, n)
+		}
+	}
+}
+
+func TestDialectArgumentCount(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New(DialectOption(DialectMySQL))
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectPrepare("SELECT").ExpectQuery().WillReturnRows(NewRows([]string{"id"}))
+	stmt, err := db.Prepare("SELECT id FROM users WHERE id = ?")
+	if err != nil {
+		t.Fatalf("unexpected error on prepare: %s", err)
+	}
+	defer stmt.Close()
+
+	if _, err := stmt.Query(1, 2); err == nil {
+		t.Error("expected an error on argument count mismatch")
+	}
+}
+
+func TestUnknownDialectOption(t *testing.T) {
+	t.Parallel()
+	if _, _, err := New(DialectOption("oracle")); err == nil {
+		t.Error("expected an error for unknown dialect")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"database/sql/driver"
 	"fmt"
+	"strings"
 	"sync"
 )
 
@@ -20,6 +21,7 @@ type mockDriver struct {
 	sync.Mutex
 	counter int
 	conns   map[string]*sqlmock
+	factory func(dsn string) (Sqlmock, error)
 }
 
 func (d *mockDriver) Open(dsn string) (driver.Conn, error) {
@@ -27,12 +29,60 @@ func (d *mockDriver) Open(dsn string) (driver.Conn, error) {
 	defer d.Unlock()
 
 	c, ok := d.conns[dsn]
+	if !ok && d.factory != nil {
+		return d.openFromFactory(dsn)
+	}
+	if !ok && strings.HasPrefix(dsn, dsnScheme) {
+		if _, err := parseDSN(dsn); err != nil {
+			return nil, err
+		}
+	}
 	if !ok {
-		return c, fmt.Errorf("expected a connection to be available, but it is not")
+		return nil, fmt.Errorf("expected a connection to be available, but it is not")
+	}
+
+	return d.connect(c)
+}
+
+// openFromFactory creates the mock for the dsn and registers it,
+// so that following connections to the same dsn use that mock
+// until all of them are closed. d must be locked.
+func (d *mockDriver) openFromFactory(dsn string) (driver.Conn, error) {
+	mock, err := d.factory(dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	c, ok := mock.(*sqlmock)
+	if !ok || c.dsn != "" || c.opened > 0 {
+		return nil, fmt.Errorf("mock factory must return a new mock created by NewConnector, for dsn: %s", dsn)
+	}
+
+	c.drv, c.dsn = d, dsn
+	d.conns[dsn] = c
+	return d.connect(c)
+}
+
+// connect opens a new connection to the given mock, unless an
+// ExpectedConnect returns an error. d must be locked.
+func (d *mockDriver) connect(c *sqlmock) (driver.Conn, error) {
+	if err := c.connect(); err != nil {
+		return nil, err
 	}
 
 	c.opened++
-	return c, nil
+	return &conn{sqlmock: c}, nil
+}
+
+// release unregisters the dsn of the mock, unless it is
+// registered for another mock already
+func (d *mockDriver) release(c *sqlmock) {
+	d.Lock()
+	defer d.Unlock()
+
+	if d.conns[c.dsn] == c {
+		delete(d.conns, c.dsn)
+	}
 }
 
 // New creates sqlmock database connection and a mock to manage expectations.
@@ -65,6 +115,9 @@ func New(options ...func(*sqlmock) error) (*sql.DB, Sqlmock, error) {
 // Note, it will error if attempted to create with an
 // already used dsn
 //
+// A DSN with the sqlmock scheme configures the mock from its query
+// parameters, see DSNOption.
+//
 // It is not recommended to use this method, unless you
 // really need it and there is no other way around.
 func NewWithDSN(dsn string, options ...func(*sqlmock) error) (*sql.DB, Sqlmock, error) {
@@ -77,5 +130,49 @@ func NewWithDSN(dsn string, options ...func(*sqlmock) error) (*sql.DB, Sqlmock,
 	pool.conns[dsn] = smock
 	pool.Unlock()
 
+	if strings.HasPrefix(dsn, dsnScheme) {
+		options = append([]func(*sqlmock) error{DSNOption(dsn)}, options...)
+	}
 	return smock.open(options)
 }
+
+// RegisterAs registers the sqlmock driver under the given driver name,
+// so that code which calls sql.Open with that driver name, for example
+// "postgres", internally gets a mock instead. Each DSN opened under that
+// name routes to the mock created with NewWithDSN for the same DSN.
+//
+// The driver name must not be already registered in the test binary,
+// that is the real driver must not be imported.
+func RegisterAs(driverName string) error {
+	return register(driverName, pool)
+}
+
+// RegisterFactoryAs registers the sqlmock driver under the given driver
+// name like RegisterAs, but every DSN opened under that name routes to
+// the mock returned by factory for that DSN. The factory must return a
+// new mock created by NewConnector, which is used for the DSN until all
+// its connections are closed.
+func RegisterFactoryAs(driverName string, factory func(dsn string) (Sqlmock, error)) error {
+	return register(driverName, &mockDriver{
+		conns:   make(map[string]*sqlmock),
+		factory: factory,
+	})
+}
+
+// registering serializes looking up the registered drivers and
+// registering one, since sql.Register panics on a duplicate name
+var registering sync.Mutex
+
+func register(driverName string, drv *mockDriver) error {
+	registering.Lock()
+	defer registering.Unlock()
+
+	for _, name := range sql.Drivers() {
+		if name == driverName {
+			return fmt.Errorf("cannot register sqlmock as driver %s, it is already registered", driverName)
+		}
+	}
+
+	sql.Register(driverName, drv)
+	return nil
+}
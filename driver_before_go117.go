@@ -0,0 +1,8 @@
+// +build !go1.17
+
+package sqlmock
+
+// closesConnector tells whether database/sql closes the connector of
+// the database, before Go 1.17 it does not, so closing the last
+// connection of the mock is taken as the database being closed
+const closesConnector = false
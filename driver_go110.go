@@ -0,0 +1,71 @@
+// +build go1.10
+
+package sqlmock
+
+import (
+	"context"
+	"database/sql/driver"
+)
+
+// connector meets http://golang.org/pkg/database/sql/driver/#Connector
+type connector struct {
+	drv  *mockDriver
+	dsn  string
+	mock *sqlmock
+	err  error
+}
+
+// OpenConnector meets http://golang.org/pkg/database/sql/driver/#DriverContext
+func (d *mockDriver) OpenConnector(dsn string) (driver.Connector, error) {
+	return &connector{drv: d, dsn: dsn}, nil
+}
+
+// Connect opens a connection to the mock of the connector, or for
+// connectors opened by DSN, to the mock registered with that DSN. The
+// connector keeps that mock, so the database may open connections to it
+// even after all of them were closed and the DSN was released.
+func (c *connector) Connect(ctx context.Context) (driver.Conn, error) {
+	c.drv.Lock()
+	mock := c.mock
+	c.drv.Unlock()
+
+	if mock == nil {
+		cn, err := c.drv.Open(c.dsn)
+		if err != nil {
+			return nil, err
+		}
+		c.drv.Lock()
+		c.mock = cn.(*conn).sqlmock
+		c.drv.Unlock()
+		return cn, nil
+	}
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	c.drv.Lock()
+	defer c.drv.Unlock()
+	return c.drv.connect(mock)
+}
+
+// Driver returns the underlying mock driver
+func (c *connector) Driver() driver.Driver {
+	return c.drv
+}
+
+// NewConnector creates a sqlmock connector and a mock to manage
+// expectations, without registering the mock in the global driver.
+// The connector is meant to be passed to sql.OpenDB, it does not
+// depend on any DSN. Accepts options, like ValueConverterOption, to
+// use a ValueConverter from a specific driver. If an option fails,
+// the error is returned when a connection is opened.
+//
+// Unlike New, the database is not pinged, so no connection is
+// opened until the database is used.
+func NewConnector(options ...func(*sqlmock) error) (driver.Connector, Sqlmock) {
+	drv := &mockDriver{conns: make(map[string]*sqlmock)}
+	smock := &sqlmock{drv: drv, ordered: true}
+	err := smock.configure(options)
+
+	return &connector{drv: drv, mock: smock, err: err}, smock
+}
@@ -0,0 +1,193 @@
+// +build go1.10
+
+package sqlmock
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNewConnector(t *testing.T) {
+	t.Parallel()
+	connector, mock := NewConnector()
+	db := sql.OpenDB(connector)
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT name FROM users").
+		WillReturnRows(NewRows([]string{"name"}).AddRow("john"))
+
+	var name string
+	if err := db.QueryRow("SELECT name FROM users").Scan(&name); err != nil {
+		t.Fatalf("error was not expected, but got: %v", err)
+	}
+	if name != "john" {
+		t.Errorf("expected name john, but got: %s", name)
+	}
+
+	if _, ok := connector.Driver().(*mockDriver); !ok {
+		t.Errorf("expected connector driver to be the mock driver, but got: %T", connector.Driver())
+	}
+
+	pool.Lock()
+	for _, c := range pool.conns {
+		if c == mock {
+			t.Error("connector mock was not expected to be registered in the global pool")
+		}
+	}
+	pool.Unlock()
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestNewConnectorExpectConnectError(t *testing.T) {
+	t.Parallel()
+	connector, mock := NewConnector()
+	db := sql.OpenDB(connector)
+	defer db.Close()
+
+	refused := errors.New("connection refused")
+	mock.ExpectConnect().WillReturnError(refused)
+	mock.ExpectConnect()
+	mock.ExpectExec("DELETE FROM users").WillReturnResult(NewResult(0, 1))
+
+	if err := db.Ping(); err != refused {
+		t.Errorf("expected connection error, but got: %v", err)
+	}
+
+	if _, err := db.Exec("DELETE FROM users"); err != nil {
+		t.Errorf("error was not expected, but got: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestWaitExpectationsWereMetIsWokenByConnect(t *testing.T) {
+	t.Parallel()
+	connector, mock := NewConnector()
+	db := sql.OpenDB(connector)
+	defer db.Close()
+
+	mock.ExpectConnect()
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		db.Ping()
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	start := time.Now()
+	if err := mock.WaitExpectationsWereMet(ctx); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+	if ctx.Err() != nil {
+		t.Errorf("expected the wait to end once connected, but it took %s", time.Since(start))
+	}
+}
+
+func TestNewConnectorOptionError(t *testing.T) {
+	t.Parallel()
+	connector, _ := NewConnector(func(*sqlmock) error {
+		return errors.New("option failed")
+	})
+	db := sql.OpenDB(connector)
+	defer db.Close()
+
+	if err := db.Ping(); err == nil || err.Error() != "option failed" {
+		t.Errorf("expected option error, but got: %v", err)
+	}
+}
+
+func TestExpectConnectWithDSN(t *testing.T) {
+	t.Parallel()
+	db, mock, err := NewWithDSN("sqlmock_db_expect_connect")
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	// hold the only open connection, so that ping needs a new one
+	conn, err := db.Conn(context.Background())
+	if err != nil {
+		t.Fatalf("error was not expected, but got: %v", err)
+	}
+	defer conn.Close()
+
+	refused := errors.New("connection refused")
+	mock.ExpectConnect().WillReturnError(refused)
+
+	if err := db.Ping(); err != refused {
+		t.Errorf("expected connection error, but got: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestRegisterFactoryAs(t *testing.T) {
+	mocks := make(map[string]Sqlmock)
+	err := RegisterFactoryAs("sqlmock_register_factory_test", func(dsn string) (Sqlmock, error) {
+		_, mock := NewConnector()
+		mock.ExpectQuery("SELECT current_database()").
+			WillReturnRows(NewRows([]string{"name"}).AddRow(dsn))
+		mock.ExpectClose()
+		mocks[dsn] = mock
+		return mock, nil
+	})
+	if err != nil {
+		t.Fatalf("expected no error, but got: %s", err)
+	}
+
+	for _, dsn := range []string{"postgres://one", "postgres://two"} {
+		db, err := sql.Open("sqlmock_register_factory_test", dsn)
+		if err != nil {
+			t.Fatalf("expected no error, but got: %s", err)
+		}
+
+		var name string
+		if err := db.QueryRow("SELECT current_database()").Scan(&name); err != nil {
+			t.Errorf("expected no error, but got: %s", err)
+		}
+		if name != dsn {
+			t.Errorf("expected query to be routed to mock of %s, but got: %s", dsn, name)
+		}
+		db.Close()
+
+		if err := mocks[dsn].ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled expectations: %s", err)
+		}
+	}
+}
+
+func TestRegisterFactoryAsRejectsRegisteredMock(t *testing.T) {
+	mdb, mock, err := NewWithDSN("sqlmock_register_factory_reject_dsn")
+	if err != nil {
+		t.Fatalf("expected no error, but got: %s", err)
+	}
+	defer mdb.Close()
+
+	err = RegisterFactoryAs("sqlmock_register_factory_reject_test", func(dsn string) (Sqlmock, error) {
+		return mock, nil
+	})
+	if err != nil {
+		t.Fatalf("expected no error, but got: %s", err)
+	}
+
+	db, err := sql.Open("sqlmock_register_factory_reject_test", "any")
+	if err != nil {
+		t.Fatalf("expected no error, but got: %s", err)
+	}
+	defer db.Close()
+
+	if err := db.Ping(); err == nil {
+		t.Error("expected an error for a mock which was not created by NewConnector")
+	}
+}
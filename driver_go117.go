@@ -0,0 +1,22 @@
+// +build go1.17
+
+package sqlmock
+
+// closesConnector tells whether database/sql closes the connector
+// of the database when the database is closed, so the mock matches
+// ExpectClose then and not when the pool closes its last connection
+const closesConnector = true
+
+// Close meets the io.Closer interface, which database/sql calls
+// once the database is closed and its connections were closed
+func (c *connector) Close() error {
+	c.drv.Lock()
+	mock := c.mock
+	c.drv.Unlock()
+
+	if mock == nil {
+		// no connection was ever opened, there is no mock to close
+		return nil
+	}
+	return mock.close()
+}
@@ -1,9 +1,11 @@
 package sqlmock
 
 import (
+	"database/sql"
 	"database/sql/driver"
 	"errors"
 	"fmt"
+	"sync"
 	"testing"
 )
 
@@ -130,3 +132,62 @@ func TestDuplicateNewDSN(t *testing.T) {
 		t.Error("expected error on NewWithDSN")
 	}
 }
+
+func TestConcurrentRegisterAs(t *testing.T) {
+	t.Parallel()
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- RegisterAs("sqlmock_concurrent_register_as_test")
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	var registered int
+	for err := range errs {
+		if err == nil {
+			registered++
+		}
+	}
+	if registered != 1 {
+		t.Errorf("expected the driver name to be registered once, but it was %d times", registered)
+	}
+}
+
+func TestRegisterAs(t *testing.T) {
+	if err := RegisterAs("sqlmock_register_as_test"); err != nil {
+		t.Fatalf("expected no error, but got: %s", err)
+	}
+	if err := RegisterAs("sqlmock_register_as_test"); err == nil {
+		t.Error("expected an error when registering the same driver name twice")
+	}
+	if err := RegisterAs("sqlmock"); err == nil {
+		t.Error("expected an error when registering an already registered driver name")
+	}
+
+	mdb, mock, err := NewWithDSN("sqlmock_register_as_dsn")
+	if err != nil {
+		t.Fatalf("expected no error, but got: %s", err)
+	}
+	defer mdb.Close()
+
+	mock.ExpectExec("UPDATE users").WillReturnResult(NewResult(0, 1))
+
+	db, err := sql.Open("sqlmock_register_as_test", "sqlmock_register_as_dsn")
+	if err != nil {
+		t.Fatalf("expected no error, but got: %s", err)
+	}
+	defer db.Close()
+
+	if _, err := db.Exec("UPDATE users SET name = 'john'"); err != nil {
+		t.Errorf("expected no error, but got: %s", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
@@ -0,0 +1,95 @@
+package sqlmock
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const dsnScheme = "sqlmock://"
+
+// DSNOption configures the mock from the query parameters of a DSN
+// in the form of:
+//
+//	sqlmock://name?matcher=equal&ordered=false&pings=true&dialect=postgres
+//
+// Supported parameters are:
+//   - matcher: regexp or equal, see QueryMatcherOption
+//   - ordered: whether expectations are matched in order, see MatchExpectationsInOrder
+//   - pings: whether pings are monitored, see MonitorPingsOption
+//   - dialect: postgres, mysql, sqlite or sqlserver, see DialectOption
+//
+// NewWithDSN applies it automatically for DSNs with the sqlmock scheme,
+// so libraries which accept only a DSN still get a configured mock.
+func DSNOption(dsn string) func(*sqlmock) error {
+	return func(s *sqlmock) error {
+		options, err := parseDSN(dsn)
+		if err != nil {
+			return err
+		}
+		for _, option := range options {
+			if err := option(s); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// parseDSN returns the options given as DSN query parameters
+func parseDSN(dsn string) ([]func(*sqlmock) error, error) {
+	if !strings.HasPrefix(dsn, dsnScheme) {
+		return nil, fmt.Errorf("invalid sqlmock dsn %q: expected %s scheme", dsn, dsnScheme)
+	}
+
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sqlmock dsn %q: %s", dsn, err)
+	}
+
+	params, err := url.ParseQuery(u.RawQuery)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sqlmock dsn %q: %s", dsn, err)
+	}
+
+	var options []func(*sqlmock) error
+	for key, values := range params {
+		value := values[len(values)-1]
+		switch key {
+		case "matcher":
+			switch value {
+			case "regexp":
+				options = append(options, QueryMatcherOption(QueryMatcherRegexp))
+			case "equal":
+				options = append(options, QueryMatcherOption(QueryMatcherEqual))
+			default:
+				return nil, fmt.Errorf("invalid sqlmock dsn %q: unknown matcher: %s", dsn, value)
+			}
+		case "ordered":
+			ordered, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid sqlmock dsn %q: ordered must be a boolean: %s", dsn, value)
+			}
+			options = append(options, func(s *sqlmock) error {
+				s.ordered = ordered
+				return nil
+			})
+		case "pings":
+			pings, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid sqlmock dsn %q: pings must be a boolean: %s", dsn, value)
+			}
+			options = append(options, MonitorPingsOption(pings))
+		case "dialect":
+			dialect, err := parseDialect(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid sqlmock dsn %q: %s", dsn, err)
+			}
+			options = append(options, DialectOption(dialect))
+		default:
+			return nil, fmt.Errorf("invalid sqlmock dsn %q: unknown parameter: %s", dsn, key)
+		}
+	}
+	return options, nil
+}
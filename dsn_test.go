@@ -0,0 +1,94 @@
+package sqlmock
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+)
+
+func TestDSNConfiguresMock(t *testing.T) {
+	t.Parallel()
+	dsn := "sqlmock://dsn_configures?matcher=equal&ordered=false&pings=true&dialect=postgres"
+	db, mock, err := NewWithDSN(dsn)
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	smock := mock.(*sqlmock)
+	if smock.ordered {
+		t.Error("expected expectations not to be matched in order")
+	}
+	if !smock.monitorPings {
+		t.Error("expected pings to be monitored")
+	}
+	if smock.dialect != DialectPostgres {
+		t.Errorf("expected postgres dialect, but got: %q", smock.dialect)
+	}
+
+	mock.ExpectExec("UPDATE users SET name = $1").WithArgs("john").WillReturnResult(NewResult(0, 1))
+	mock.ExpectPing()
+	if err := db.Ping(); err != nil {
+		t.Errorf("unexpected error on ping: %s", err)
+	}
+	if _, err := db.Exec("UPDATE users SET name = $1", "john"); err != nil {
+		t.Errorf("unexpected error on exec: %s", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestDSNOptionsOverrideDSN(t *testing.T) {
+	t.Parallel()
+	db, mock, err := NewWithDSN("sqlmock://dsn_override?ordered=false", func(s *sqlmock) error {
+		s.ordered = true
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	if !mock.(*sqlmock).ordered {
+		t.Error("expected explicit option to override the dsn")
+	}
+}
+
+func TestInvalidDSN(t *testing.T) {
+	t.Parallel()
+	cases := map[string]string{
+		"sqlmock://invalid_param?colour=red":       "unknown parameter: colour",
+		"sqlmock://invalid_matcher?matcher=fuzzy":  "unknown matcher: fuzzy",
+		"sqlmock://invalid_ordered?ordered=maybe":  "ordered must be a boolean",
+		"sqlmock://invalid_pings?pings=2x":         "pings must be a boolean",
+		"sqlmock://invalid_dialect?dialect=oracle": "unknown dialect: oracle",
+	}
+	for dsn, expected := range cases {
+		_, _, err := NewWithDSN(dsn)
+		if err == nil {
+			t.Errorf("expected an error for dsn %q", dsn)
+			continue
+		}
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("expected error for dsn %q to contain %q, but got: %s", dsn, expected, err)
+		}
+		if _, _, err := NewWithDSN(dsn); err == nil || strings.Contains(err.Error(), "same dsn") {
+			t.Errorf("expected the invalid dsn %q to be released, but got: %v", dsn, err)
+		}
+	}
+}
+
+func TestOpenMalformedDSN(t *testing.T) {
+	t.Parallel()
+	db, err := sql.Open("sqlmock", "sqlmock://not_registered?matcher=fuzzy")
+	if err != nil {
+		t.Fatalf("unexpected error on open: %s", err)
+	}
+	defer db.Close()
+
+	err = db.Ping()
+	if err == nil || !strings.Contains(err.Error(), "invalid sqlmock dsn") {
+		t.Errorf("expected an invalid dsn error, but got: %v", err)
+	}
+}
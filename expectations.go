@@ -3,6 +3,7 @@ package sqlmock
 import (
 	"database/sql/driver"
 	"fmt"
+	"regexp"
 	"strings"
 	"sync"
 	"time"
@@ -10,7 +11,9 @@ import (
 
 // an expectation interface
 type expectation interface {
+	kind() CallKind
 	fulfilled() bool
+	common() *commonExpectation
 	Lock()
 	Unlock()
 	String() string
@@ -22,12 +25,24 @@ type commonExpectation struct {
 	sync.Mutex
 	triggered bool
 	err       error
+	cancelErr error
+	declared  string // file and line which registered the expectation
+	invalid   error  // the reason why the expectation may never match
+	mock      *sqlmock
+	after     []expectation  // predecessors, guarded by the mutex of the mock
+	epoch     int            // incremented when the mock is reset or restored
+	breaks    bool           // breaks the connection of the matching call
+	compiled  *regexp.Regexp // the expected SQL compiled by the query matcher
 }
 
 func (e *commonExpectation) fulfilled() bool {
 	return e.triggered
 }
 
+func (e *commonExpectation) common() *commonExpectation {
+	return e
+}
+
 // ExpectedClose is used to manage *sql.DB.Close expectation
 // returned by *Sqlmock.ExpectClose.
 type ExpectedClose struct {
@@ -36,10 +51,23 @@ type ExpectedClose struct {
 
 // WillReturnError allows to set an error for *sql.DB.Close action
 func (e *ExpectedClose) WillReturnError(err error) *ExpectedClose {
+	e.Lock()
+	defer e.Unlock()
 	e.err = err
 	return e
 }
 
+// After declares, that the expectation may only be matched once
+// all the given expectations were triggered
+func (e *ExpectedClose) After(expectations ...Expectation) *ExpectedClose {
+	e.mock.after(e, expectations)
+	return e
+}
+
+func (e *ExpectedClose) kind() CallKind {
+	return CallClose
+}
+
 // String returns string representation
 func (e *ExpectedClose) String() string {
 	msg := "ExpectedClose => expecting database Close"
@@ -49,19 +77,78 @@ func (e *ExpectedClose) String() string {
 	return msg
 }
 
+// ExpectedConnect is used to manage a connection opened by the driver.
+// Returned by *Sqlmock.ExpectConnect.
+type ExpectedConnect struct {
+	commonExpectation
+}
+
+// WillReturnError allows to set an error for opening the connection
+func (e *ExpectedConnect) WillReturnError(err error) *ExpectedConnect {
+	e.Lock()
+	defer e.Unlock()
+	e.err = err
+	return e
+}
+
+// After declares, that the expectation may only be matched once
+// all the given expectations were triggered
+func (e *ExpectedConnect) After(expectations ...Expectation) *ExpectedConnect {
+	e.mock.after(e, expectations)
+	return e
+}
+
+func (e *ExpectedConnect) kind() CallKind {
+	return callConnect
+}
+
+// String returns string representation
+func (e *ExpectedConnect) String() string {
+	msg := "ExpectedConnect => expecting a connection to be opened"
+	if e.err != nil {
+		msg += fmt.Sprintf(", which should return error: %s", e.err)
+	}
+	return msg
+}
+
 // ExpectedBegin is used to manage *sql.DB.Begin expectation
 // returned by *Sqlmock.ExpectBegin.
 type ExpectedBegin struct {
 	commonExpectation
-	delay time.Duration
+	blocker
+	delay *time.Duration
 }
 
 // WillReturnError allows to set an error for *sql.DB.Begin action
 func (e *ExpectedBegin) WillReturnError(err error) *ExpectedBegin {
+	e.Lock()
+	defer e.Unlock()
 	e.err = err
 	return e
 }
 
+// After declares, that the expectation may only be matched once
+// all the given expectations were triggered
+func (e *ExpectedBegin) After(expectations ...Expectation) *ExpectedBegin {
+	e.mock.after(e, expectations)
+	return e
+}
+
+// WillBreakConnection breaks the connection as soon as Begin matches
+// the expectation. Begin still gets the mocked response, but the other
+// calls on the connection, Commit and Rollback of the transaction
+// included, return driver.ErrBadConn, so database/sql discards it.
+func (e *ExpectedBegin) WillBreakConnection() *ExpectedBegin {
+	e.Lock()
+	defer e.Unlock()
+	e.breaks = true
+	return e
+}
+
+func (e *ExpectedBegin) kind() CallKind {
+	return CallBegin
+}
+
 // String returns string representation
 func (e *ExpectedBegin) String() string {
 	msg := "ExpectedBegin => expecting database transaction Begin"
@@ -71,31 +158,126 @@ func (e *ExpectedBegin) String() string {
 	return msg
 }
 
+// WillReturnCancelError allows to set an error which is returned
+// instead of the default cancellation error, when the context is
+// done while the Begin is delayed
+func (e *ExpectedBegin) WillReturnCancelError(err error) *ExpectedBegin {
+	e.Lock()
+	defer e.Unlock()
+	e.cancelErr = err
+	return e
+}
+
 // WillDelayFor allows to specify duration for which it will delay
 // result. May be used together with Context
 func (e *ExpectedBegin) WillDelayFor(duration time.Duration) *ExpectedBegin {
-	e.delay = duration
+	e.Lock()
+	defer e.Unlock()
+	e.delay = &duration
+	return e
+}
+
+// WillBlockUntil holds a call, which matched the expectation, in flight
+// until the channel is closed. May be used together with Context
+func (e *ExpectedBegin) WillBlockUntil(release <-chan struct{}) *ExpectedBegin {
+	e.Lock()
+	defer e.Unlock()
+	e.release = release
 	return e
 }
 
+// Reached returns a channel, which is closed once a Begin call
+// matched the expectation, before it is blocked or delayed
+func (e *ExpectedBegin) Reached() <-chan struct{} {
+	e.Lock()
+	defer e.Unlock()
+	return e.reachedChan()
+}
+
 // ExpectedCommit is used to manage *sql.Tx.Commit expectation
 // returned by *Sqlmock.ExpectCommit.
 type ExpectedCommit struct {
 	commonExpectation
+	blocker
+	sequence
+	delay *time.Duration
 }
 
 // WillReturnError allows to set an error for *sql.Tx.Close action
 func (e *ExpectedCommit) WillReturnError(err error) *ExpectedCommit {
+	e.Lock()
+	defer e.Unlock()
 	e.err = err
 	return e
 }
 
+// WillReturnCancelError allows to set an error which is returned
+// instead of the default cancellation error, when the context is
+// done while the Commit is delayed
+func (e *ExpectedCommit) WillReturnCancelError(err error) *ExpectedCommit {
+	e.Lock()
+	defer e.Unlock()
+	e.cancelErr = err
+	return e
+}
+
+// WillDelayFor allows to specify duration for which it will delay
+// result. May be used together with Context
+func (e *ExpectedCommit) WillDelayFor(duration time.Duration) *ExpectedCommit {
+	e.Lock()
+	defer e.Unlock()
+	e.delay = &duration
+	return e
+}
+
+// WillBlockUntil holds a call, which matched the expectation, in flight
+// until the channel is closed. May be used together with Context
+func (e *ExpectedCommit) WillBlockUntil(release <-chan struct{}) *ExpectedCommit {
+	e.Lock()
+	defer e.Unlock()
+	e.release = release
+	return e
+}
+
+// Reached returns a channel, which is closed once a Commit call
+// matched the expectation, before it is blocked or delayed
+func (e *ExpectedCommit) Reached() <-chan struct{} {
+	e.Lock()
+	defer e.Unlock()
+	return e.reachedChan()
+}
+
+// After declares, that the expectation may only be matched once
+// all the given expectations were triggered
+func (e *ExpectedCommit) After(expectations ...Expectation) *ExpectedCommit {
+	e.mock.after(e, expectations)
+	return e
+}
+
+// WillBreakConnection breaks the connection as soon as Commit matches
+// the expectation. Commit still gets the mocked response, but the other
+// calls on the connection return driver.ErrBadConn, so database/sql
+// discards it.
+func (e *ExpectedCommit) WillBreakConnection() *ExpectedCommit {
+	e.Lock()
+	defer e.Unlock()
+	e.breaks = true
+	return e
+}
+
+func (e *ExpectedCommit) kind() CallKind {
+	return CallCommit
+}
+
 // String returns string representation
 func (e *ExpectedCommit) String() string {
 	msg := "ExpectedCommit => expecting transaction Commit"
 	if e.err != nil {
 		msg += fmt.Sprintf(", which should return error: %s", e.err)
 	}
+	if n := len(e.responses); n > 0 {
+		msg += fmt.Sprintf(", which should respond with a sequence of %d responses, %d were returned", n, e.calls)
+	}
 	return msg
 }
 
@@ -103,14 +285,58 @@ func (e *ExpectedCommit) String() string {
 // returned by *Sqlmock.ExpectRollback.
 type ExpectedRollback struct {
 	commonExpectation
+	delay *time.Duration
 }
 
 // WillReturnError allows to set an error for *sql.Tx.Rollback action
 func (e *ExpectedRollback) WillReturnError(err error) *ExpectedRollback {
+	e.Lock()
+	defer e.Unlock()
 	e.err = err
 	return e
 }
 
+// WillReturnCancelError allows to set an error which is returned
+// instead of the default cancellation error, when the context is
+// done while the Rollback is delayed
+func (e *ExpectedRollback) WillReturnCancelError(err error) *ExpectedRollback {
+	e.Lock()
+	defer e.Unlock()
+	e.cancelErr = err
+	return e
+}
+
+// WillDelayFor allows to specify duration for which it will delay
+// result. May be used together with Context
+func (e *ExpectedRollback) WillDelayFor(duration time.Duration) *ExpectedRollback {
+	e.Lock()
+	defer e.Unlock()
+	e.delay = &duration
+	return e
+}
+
+// After declares, that the expectation may only be matched once
+// all the given expectations were triggered
+func (e *ExpectedRollback) After(expectations ...Expectation) *ExpectedRollback {
+	e.mock.after(e, expectations)
+	return e
+}
+
+// WillBreakConnection breaks the connection as soon as Rollback matches
+// the expectation. Rollback still gets the mocked response, but the
+// other calls on the connection return driver.ErrBadConn, so database/sql
+// discards it.
+func (e *ExpectedRollback) WillBreakConnection() *ExpectedRollback {
+	e.Lock()
+	defer e.Unlock()
+	e.breaks = true
+	return e
+}
+
+func (e *ExpectedRollback) kind() CallKind {
+	return CallRollback
+}
+
 // String returns string representation
 func (e *ExpectedRollback) String() string {
 	msg := "ExpectedRollback => expecting transaction Rollback"
@@ -125,8 +351,10 @@ func (e *ExpectedRollback) String() string {
 // Returned by *Sqlmock.ExpectQuery.
 type ExpectedQuery struct {
 	queryBasedExpectation
+	blocker
+	sequence
 	rows             driver.Rows
-	delay            time.Duration
+	delay            *time.Duration
 	rowsMustBeClosed bool
 	rowsWereClosed   bool
 }
@@ -135,29 +363,87 @@ type ExpectedQuery struct {
 // if at least one argument does not match, it will return an error. For specific
 // arguments an sqlmock.Argument interface can be used to match an argument.
 func (e *ExpectedQuery) WithArgs(args ...driver.Value) *ExpectedQuery {
+	e.Lock()
+	defer e.Unlock()
 	e.args = args
 	return e
 }
 
 // RowsWillBeClosed expects this query rows to be closed.
 func (e *ExpectedQuery) RowsWillBeClosed() *ExpectedQuery {
+	e.Lock()
+	defer e.Unlock()
 	e.rowsMustBeClosed = true
 	return e
 }
 
 // WillReturnError allows to set an error for expected database query
 func (e *ExpectedQuery) WillReturnError(err error) *ExpectedQuery {
+	e.Lock()
+	defer e.Unlock()
 	e.err = err
 	return e
 }
 
+// WillReturnCancelError allows to set an error which is returned
+// instead of the default cancellation error, when the context is
+// done while the query is delayed
+func (e *ExpectedQuery) WillReturnCancelError(err error) *ExpectedQuery {
+	e.Lock()
+	defer e.Unlock()
+	e.cancelErr = err
+	return e
+}
+
 // WillDelayFor allows to specify duration for which it will delay
 // result. May be used together with Context
 func (e *ExpectedQuery) WillDelayFor(duration time.Duration) *ExpectedQuery {
-	e.delay = duration
+	e.Lock()
+	defer e.Unlock()
+	e.delay = &duration
+	return e
+}
+
+// WillBlockUntil holds a call, which matched the expectation, in flight
+// until the channel is closed. May be used together with Context
+func (e *ExpectedQuery) WillBlockUntil(release <-chan struct{}) *ExpectedQuery {
+	e.Lock()
+	defer e.Unlock()
+	e.release = release
+	return e
+}
+
+// Reached returns a channel, which is closed once a query call
+// matched the expectation, before it is blocked or delayed
+func (e *ExpectedQuery) Reached() <-chan struct{} {
+	e.Lock()
+	defer e.Unlock()
+	return e.reachedChan()
+}
+
+// After declares, that the expectation may only be matched once
+// all the given expectations were triggered
+func (e *ExpectedQuery) After(expectations ...Expectation) *ExpectedQuery {
+	e.mock.after(e, expectations)
 	return e
 }
 
+// WillBreakConnection breaks the connection as soon as the query matches
+// the expectation, before it is blocked or delayed. The query still gets
+// its rows or error, but the other calls on the connection return
+// driver.ErrBadConn and it reports itself invalid, so database/sql
+// discards it. It allows to simulate a connection dropped mid transaction.
+func (e *ExpectedQuery) WillBreakConnection() *ExpectedQuery {
+	e.Lock()
+	defer e.Unlock()
+	e.breaks = true
+	return e
+}
+
+func (e *ExpectedQuery) kind() CallKind {
+	return CallQuery
+}
+
 // String returns string representation
 func (e *ExpectedQuery) String() string {
 	msg := "ExpectedQuery => expecting Query, QueryContext or QueryRow which:"
@@ -181,6 +467,10 @@ func (e *ExpectedQuery) String() string {
 		msg += fmt.Sprintf("\n  - should return error: %s", e.err)
 	}
 
+	if n := len(e.responses); n > 0 {
+		msg += fmt.Sprintf("\n  - should respond with a sequence of %d responses, %d were returned", n, e.calls)
+	}
+
 	return msg
 }
 
@@ -188,31 +478,89 @@ func (e *ExpectedQuery) String() string {
 // Returned by *Sqlmock.ExpectExec.
 type ExpectedExec struct {
 	queryBasedExpectation
+	blocker
+	sequence
 	result driver.Result
-	delay  time.Duration
+	delay  *time.Duration
 }
 
 // WithArgs will match given expected args to actual database exec operation arguments.
 // if at least one argument does not match, it will return an error. For specific
 // arguments an sqlmock.Argument interface can be used to match an argument.
 func (e *ExpectedExec) WithArgs(args ...driver.Value) *ExpectedExec {
+	e.Lock()
+	defer e.Unlock()
 	e.args = args
 	return e
 }
 
 // WillReturnError allows to set an error for expected database exec action
 func (e *ExpectedExec) WillReturnError(err error) *ExpectedExec {
+	e.Lock()
+	defer e.Unlock()
 	e.err = err
 	return e
 }
 
+// WillReturnCancelError allows to set an error which is returned
+// instead of the default cancellation error, when the context is
+// done while the exec is delayed
+func (e *ExpectedExec) WillReturnCancelError(err error) *ExpectedExec {
+	e.Lock()
+	defer e.Unlock()
+	e.cancelErr = err
+	return e
+}
+
 // WillDelayFor allows to specify duration for which it will delay
 // result. May be used together with Context
 func (e *ExpectedExec) WillDelayFor(duration time.Duration) *ExpectedExec {
-	e.delay = duration
+	e.Lock()
+	defer e.Unlock()
+	e.delay = &duration
 	return e
 }
 
+// WillBlockUntil holds a call, which matched the expectation, in flight
+// until the channel is closed. May be used together with Context
+func (e *ExpectedExec) WillBlockUntil(release <-chan struct{}) *ExpectedExec {
+	e.Lock()
+	defer e.Unlock()
+	e.release = release
+	return e
+}
+
+// Reached returns a channel, which is closed once a exec call
+// matched the expectation, before it is blocked or delayed
+func (e *ExpectedExec) Reached() <-chan struct{} {
+	e.Lock()
+	defer e.Unlock()
+	return e.reachedChan()
+}
+
+// After declares, that the expectation may only be matched once
+// all the given expectations were triggered
+func (e *ExpectedExec) After(expectations ...Expectation) *ExpectedExec {
+	e.mock.after(e, expectations)
+	return e
+}
+
+// WillBreakConnection breaks the connection as soon as the exec matches
+// the expectation, before it is blocked or delayed. The exec still gets
+// its result or error, but the other calls on the connection return
+// driver.ErrBadConn and it reports itself invalid, so database/sql
+// discards it. It allows to simulate a connection dropped mid transaction.
+func (e *ExpectedExec) WillBreakConnection() *ExpectedExec {
+	e.Lock()
+	defer e.Unlock()
+	e.breaks = true
+	return e
+}
+
+func (e *ExpectedExec) kind() CallKind {
+	return CallExec
+}
+
 // String returns string representation
 func (e *ExpectedExec) String() string {
 	msg := "ExpectedExec => expecting Exec or ExecContext which:"
@@ -244,6 +592,10 @@ func (e *ExpectedExec) String() string {
 		msg += fmt.Sprintf("\n  - should return error: %s", e.err)
 	}
 
+	if n := len(e.responses); n > 0 {
+		msg += fmt.Sprintf("\n  - should respond with a sequence of %d responses, %d were returned", n, e.calls)
+	}
+
 	return msg
 }
 
@@ -252,6 +604,8 @@ func (e *ExpectedExec) String() string {
 // to build a corresponding result. Or if actions needs to be tested against errors
 // sqlmock.NewErrorResult(err error) to return a given error.
 func (e *ExpectedExec) WillReturnResult(result driver.Result) *ExpectedExec {
+	e.Lock()
+	defer e.Unlock()
 	e.result = result
 	return e
 }
@@ -260,37 +614,72 @@ func (e *ExpectedExec) WillReturnResult(result driver.Result) *ExpectedExec {
 // Returned by *Sqlmock.ExpectPrepare.
 type ExpectedPrepare struct {
 	commonExpectation
-	mock         *sqlmock
+	blocker
 	expectSQL    string
 	statement    driver.Stmt
 	closeErr     error
 	mustBeClosed bool
 	wasClosed    bool
-	delay        time.Duration
+	delay        *time.Duration
 }
 
 // WillReturnError allows to set an error for the expected *sql.DB.Prepare or *sql.Tx.Prepare action.
 func (e *ExpectedPrepare) WillReturnError(err error) *ExpectedPrepare {
+	e.Lock()
+	defer e.Unlock()
 	e.err = err
 	return e
 }
 
 // WillReturnCloseError allows to set an error for this prepared statement Close action
 func (e *ExpectedPrepare) WillReturnCloseError(err error) *ExpectedPrepare {
+	e.Lock()
+	defer e.Unlock()
 	e.closeErr = err
 	return e
 }
 
+// WillReturnCancelError allows to set an error which is returned
+// instead of the default cancellation error, when the context is
+// done while the Prepare is delayed
+func (e *ExpectedPrepare) WillReturnCancelError(err error) *ExpectedPrepare {
+	e.Lock()
+	defer e.Unlock()
+	e.cancelErr = err
+	return e
+}
+
 // WillDelayFor allows to specify duration for which it will delay
 // result. May be used together with Context
 func (e *ExpectedPrepare) WillDelayFor(duration time.Duration) *ExpectedPrepare {
-	e.delay = duration
+	e.Lock()
+	defer e.Unlock()
+	e.delay = &duration
+	return e
+}
+
+// WillBlockUntil holds a call, which matched the expectation, in flight
+// until the channel is closed. May be used together with Context
+func (e *ExpectedPrepare) WillBlockUntil(release <-chan struct{}) *ExpectedPrepare {
+	e.Lock()
+	defer e.Unlock()
+	e.release = release
 	return e
 }
 
+// Reached returns a channel, which is closed once a Prepare call
+// matched the expectation, before it is blocked or delayed
+func (e *ExpectedPrepare) Reached() <-chan struct{} {
+	e.Lock()
+	defer e.Unlock()
+	return e.reachedChan()
+}
+
 // WillBeClosed expects this prepared statement to
 // be closed.
 func (e *ExpectedPrepare) WillBeClosed() *ExpectedPrepare {
+	e.Lock()
+	defer e.Unlock()
 	e.mustBeClosed = true
 	return e
 }
@@ -301,7 +690,7 @@ func (e *ExpectedPrepare) ExpectQuery() *ExpectedQuery {
 	eq := &ExpectedQuery{}
 	eq.expectSQL = e.expectSQL
 	eq.converter = e.mock.converter
-	e.mock.expected = append(e.mock.expected, eq)
+	e.mock.expect(eq)
 	return eq
 }
 
@@ -311,10 +700,31 @@ func (e *ExpectedPrepare) ExpectExec() *ExpectedExec {
 	eq := &ExpectedExec{}
 	eq.expectSQL = e.expectSQL
 	eq.converter = e.mock.converter
-	e.mock.expected = append(e.mock.expected, eq)
+	e.mock.expect(eq)
 	return eq
 }
 
+// After declares, that the expectation may only be matched once
+// all the given expectations were triggered
+func (e *ExpectedPrepare) After(expectations ...Expectation) *ExpectedPrepare {
+	e.mock.after(e, expectations)
+	return e
+}
+
+// WillBreakConnection breaks the connection as soon as Prepare matches
+// the expectation. The statement is still prepared, but its queries and
+// execs, like the other calls on the connection, return driver.ErrBadConn.
+func (e *ExpectedPrepare) WillBreakConnection() *ExpectedPrepare {
+	e.Lock()
+	defer e.Unlock()
+	e.breaks = true
+	return e
+}
+
+func (e *ExpectedPrepare) kind() CallKind {
+	return CallPrepare
+}
+
 // String returns string representation
 func (e *ExpectedPrepare) String() string {
 	msg := "ExpectedPrepare => expecting Prepare statement which:"
@@ -344,22 +754,57 @@ type queryBasedExpectation struct {
 // Returned by *Sqlmock.ExpectPing.
 type ExpectedPing struct {
 	commonExpectation
-	delay time.Duration
+	delay *time.Duration
+}
+
+// WillReturnCancelError allows to set an error which is returned
+// instead of the default cancellation error, when the context is
+// done while the ping is delayed
+func (e *ExpectedPing) WillReturnCancelError(err error) *ExpectedPing {
+	e.Lock()
+	defer e.Unlock()
+	e.cancelErr = err
+	return e
 }
 
 // WillDelayFor allows to specify duration for which it will delay result. May
 // be used together with Context.
 func (e *ExpectedPing) WillDelayFor(duration time.Duration) *ExpectedPing {
-	e.delay = duration
+	e.Lock()
+	defer e.Unlock()
+	e.delay = &duration
 	return e
 }
 
 // WillReturnError allows to set an error for expected database ping
 func (e *ExpectedPing) WillReturnError(err error) *ExpectedPing {
+	e.Lock()
+	defer e.Unlock()
 	e.err = err
 	return e
 }
 
+// After declares, that the expectation may only be matched once
+// all the given expectations were triggered
+func (e *ExpectedPing) After(expectations ...Expectation) *ExpectedPing {
+	e.mock.after(e, expectations)
+	return e
+}
+
+// WillBreakConnection breaks the connection as soon as the ping matches
+// the expectation. The ping still gets the mocked response, but the
+// other calls on the connection return driver.ErrBadConn.
+func (e *ExpectedPing) WillBreakConnection() *ExpectedPing {
+	e.Lock()
+	defer e.Unlock()
+	e.breaks = true
+	return e
+}
+
+func (e *ExpectedPing) kind() CallKind {
+	return CallPing
+}
+
 // String returns string representation
 func (e *ExpectedPing) String() string {
 	msg := "ExpectedPing => expecting database Ping"
@@ -368,3 +813,93 @@ func (e *ExpectedPing) String() string {
 	}
 	return msg
 }
+
+// ExpectedResetSession is used to manage the session reset of a pooled
+// connection, which database/sql requests before reusing it.
+// Returned by *Sqlmock.ExpectResetSession.
+type ExpectedResetSession struct {
+	commonExpectation
+}
+
+// WillReturnError allows to set an error for the session reset,
+// driver.ErrBadConn makes database/sql discard the connection
+func (e *ExpectedResetSession) WillReturnError(err error) *ExpectedResetSession {
+	e.Lock()
+	defer e.Unlock()
+	e.err = err
+	return e
+}
+
+// After declares, that the expectation may only be matched once
+// all the given expectations were triggered
+func (e *ExpectedResetSession) After(expectations ...Expectation) *ExpectedResetSession {
+	e.mock.after(e, expectations)
+	return e
+}
+
+// WillBreakConnection breaks the connection as soon as its session
+// reset matches the expectation. The reset still gets the mocked
+// response, but the calls on the reused connection return
+// driver.ErrBadConn.
+func (e *ExpectedResetSession) WillBreakConnection() *ExpectedResetSession {
+	e.Lock()
+	defer e.Unlock()
+	e.breaks = true
+	return e
+}
+
+func (e *ExpectedResetSession) kind() CallKind {
+	return callResetSession
+}
+
+// String returns string representation
+func (e *ExpectedResetSession) String() string {
+	msg := "ExpectedResetSession => expecting connection session reset"
+	if e.err != nil {
+		msg += fmt.Sprintf(", which should return error: %s", e.err)
+	}
+	return msg
+}
+
+// ExpectedIsValid is used to manage the validation of a connection,
+// which database/sql requests when the connection is returned to the pool.
+// Returned by *Sqlmock.ExpectIsValid.
+type ExpectedIsValid struct {
+	commonExpectation
+	valid bool
+}
+
+// WillReturnValid allows to set whether the connection is reported
+// as valid, if not database/sql discards the connection
+func (e *ExpectedIsValid) WillReturnValid(valid bool) *ExpectedIsValid {
+	e.Lock()
+	defer e.Unlock()
+	e.valid = valid
+	return e
+}
+
+// After declares, that the expectation may only be matched once
+// all the given expectations were triggered
+func (e *ExpectedIsValid) After(expectations ...Expectation) *ExpectedIsValid {
+	e.mock.after(e, expectations)
+	return e
+}
+
+// WillBreakConnection breaks the connection as soon as its validation
+// matches the expectation. The validation still reports the mocked
+// validity, but the following ones report the connection as invalid.
+func (e *ExpectedIsValid) WillBreakConnection() *ExpectedIsValid {
+	e.Lock()
+	defer e.Unlock()
+	e.breaks = true
+	return e
+}
+
+func (e *ExpectedIsValid) kind() CallKind {
+	return callIsValid
+}
+
+// String returns string representation
+func (e *ExpectedIsValid) String() string {
+	return fmt.Sprintf("ExpectedIsValid => expecting connection validation, which should report valid: %t", e.valid)
+}
@@ -11,7 +11,9 @@ import (
 // WillReturnRows specifies the set of resulting rows that will be returned
 // by the triggered query
 func (e *ExpectedQuery) WillReturnRows(rows *Rows) *ExpectedQuery {
-	e.rows = &rowSets{sets: []*Rows{rows}, ex: e}
+	e.Lock()
+	defer e.Unlock()
+	e.rows = &rowSets{sets: []*Rows{rows}, ex: e, epoch: e.epoch}
 	return e
 }
 
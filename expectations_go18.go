@@ -12,6 +12,9 @@ import (
 // WillReturnRows specifies the set of resulting rows that will be returned
 // by the triggered query
 func (e *ExpectedQuery) WillReturnRows(rows ...*Rows) *ExpectedQuery {
+	e.Lock()
+	defer e.Unlock()
+
 	defs := 0
 	sets := make([]*Rows, len(rows))
 	for i, r := range rows {
@@ -21,9 +24,9 @@ func (e *ExpectedQuery) WillReturnRows(rows ...*Rows) *ExpectedQuery {
 		}
 	}
 	if defs > 0 && defs == len(sets) {
-		e.rows = &rowSetsWithDefinition{&rowSets{sets: sets, ex: e}}
+		e.rows = &rowSetsWithDefinition{&rowSets{sets: sets, ex: e, epoch: e.epoch}}
 	} else {
-		e.rows = &rowSets{sets: sets, ex: e}
+		e.rows = &rowSets{sets: sets, ex: e, epoch: e.epoch}
 	}
 	return e
 }
@@ -49,8 +49,8 @@ func TestBuildQuery(t *testing.T) {
 
 	`
 
-	mock.ExpectQuery(query)
-	mock.ExpectExec(query)
+	mock.ExpectQuery(query).WillReturnRows(NewRows([]string{"name"}))
+	mock.ExpectExec(query).WillReturnResult(NewResult(0, 0))
 	mock.ExpectPrepare(query)
 
 	db.QueryRow(query)
@@ -0,0 +1,170 @@
+package sqlmock
+
+import (
+	"database/sql/driver"
+	"log"
+	"math/rand"
+	"sync"
+)
+
+// Faults is a seeded fault injection model. When passed to the mock
+// through FaultInjectionOption, driver calls randomly fail with the
+// configured probabilities, regardless of the expectations. A call
+// which fails by injected fault does not consume any expectation.
+//
+// Every injected fault is logged together with the seed and the
+// number of the random decision, given the same seed and the same
+// order of calls the very same faults are injected, so a failure
+// can be replayed exactly.
+type Faults struct {
+	mu        sync.Mutex
+	seed      int64
+	rnd       *rand.Rand
+	decisions int
+	calls     map[CallKind]*fault
+	rows      *fault
+	result    *fault
+	logf      func(format string, v ...interface{})
+}
+
+type fault struct {
+	probability float64
+	errs        []error
+}
+
+// NewFaults creates a fault injection model
+// which random decisions are made from the
+// given seed
+func NewFaults(seed int64) *Faults {
+	return &Faults{
+		seed:  seed,
+		rnd:   rand.New(rand.NewSource(seed)),
+		calls: make(map[CallKind]*fault),
+		logf:  log.Printf,
+	}
+}
+
+// Seed returns the seed of the fault injection model
+func (f *Faults) Seed() int64 {
+	return f.seed
+}
+
+// Fail makes calls of the given kind fail with the given probability,
+// between 0 and 1. The returned error is picked randomly from errs, if
+// none is given driver.ErrBadConn is returned.
+func (f *Faults) Fail(kind CallKind, probability float64, errs ...error) *Faults {
+	f.calls[kind] = newFault(probability, errs)
+	return f
+}
+
+// FailRows makes every rows.Next call of the queried rows fail
+// with the given probability, so iteration stops mid stream.
+func (f *Faults) FailRows(probability float64, errs ...error) *Faults {
+	f.rows = newFault(probability, errs)
+	return f
+}
+
+// FailResult makes LastInsertId and RowsAffected of
+// exec results fail with the given probability.
+func (f *Faults) FailResult(probability float64, errs ...error) *Faults {
+	f.result = newFault(probability, errs)
+	return f
+}
+
+// Logger sets the function used to log injected
+// faults, by default it is log.Printf
+func (f *Faults) Logger(logf func(format string, v ...interface{})) *Faults {
+	f.logf = logf
+	return f
+}
+
+func newFault(probability float64, errs []error) *fault {
+	if len(errs) == 0 {
+		errs = []error{driver.ErrBadConn}
+	}
+	return &fault{probability: probability, errs: errs}
+}
+
+// inject decides whether the fault happens and returns its error
+func (f *Faults) inject(target string, ft *fault) error {
+	if ft == nil {
+		return nil
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.decisions++
+	if f.rnd.Float64() >= ft.probability {
+		return nil
+	}
+
+	err := ft.errs[f.rnd.Intn(len(ft.errs))]
+	f.logf("sqlmock: injected fault into %s at decision %d with seed %d: %v", target, f.decisions, f.seed, err)
+	return err
+}
+
+func (f *Faults) call(kind CallKind) error {
+	return f.inject(kind.String(), f.calls[kind])
+}
+
+func (f *Faults) row() error {
+	return f.inject("Rows.Next", f.rows)
+}
+
+func (f *Faults) wrapResult(res driver.Result) driver.Result {
+	if f.result == nil || res == nil {
+		return res
+	}
+	return &faultyResult{Result: res, faults: f}
+}
+
+// faultyResult injects faults into an exec result
+type faultyResult struct {
+	driver.Result
+	faults *Faults
+}
+
+func (r *faultyResult) LastInsertId() (int64, error) {
+	if err := r.faults.inject("Result.LastInsertId", r.faults.result); err != nil {
+		return 0, err
+	}
+	return r.Result.LastInsertId()
+}
+
+func (r *faultyResult) RowsAffected() (int64, error) {
+	if err := r.faults.inject("Result.RowsAffected", r.faults.result); err != nil {
+		return 0, err
+	}
+	return r.Result.RowsAffected()
+}
+
+// injectFault returns an error for the call of the given kind,
+// when the fault injection model of the mock decides it fails.
+// An injected driver.ErrBadConn discards the connection.
+func (c *conn) injectFault(kind CallKind) error {
+	if c.faults == nil {
+		return nil
+	}
+	err := c.faults.call(kind)
+	c.discardIf(err)
+	return err
+}
+
+// injectRowFault returns an error for the next row, when
+// the fault injection model of the mock decides it fails
+func (c *sqlmock) injectRowFault() error {
+	if c.faults == nil {
+		return nil
+	}
+	return c.faults.row()
+}
+
+// wrapResult makes the exec result subject
+// to the fault injection model of the mock
+func (c *sqlmock) wrapResult(res driver.Result) driver.Result {
+	if c.faults == nil {
+		return res
+	}
+	return c.faults.wrapResult(res)
+}
@@ -0,0 +1,134 @@
+// +build go1.8
+
+package sqlmock
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestFaultsAreReproducible(t *testing.T) {
+	t.Parallel()
+	var logs []string
+	logf := func(format string, v ...interface{}) {
+		logs = append(logs, fmt.Sprintf(format, v...))
+	}
+
+	a := NewFaults(99).Fail(CallExec, 0.5).Logger(logf)
+	b := NewFaults(99).Fail(CallExec, 0.5).Logger(func(string, ...interface{}) {})
+
+	var failed int
+	for i := 0; i < 100; i++ {
+		ea, eb := a.call(CallExec), b.call(CallExec)
+		if ea != eb {
+			t.Fatalf("expected the same fault for the same seed at call %d, but got %v and %v", i, ea, eb)
+		}
+		if ea != nil {
+			failed++
+			if ea != driver.ErrBadConn {
+				t.Fatalf("expected driver.ErrBadConn by default, but got %v", ea)
+			}
+		}
+		if err := a.call(CallQuery); err != nil {
+			t.Fatalf("query was not configured to fail, but got %v", err)
+		}
+	}
+
+	if failed == 0 || failed == 100 {
+		t.Errorf("expected some of the calls to fail, but %d of 100 failed", failed)
+	}
+	if len(logs) != failed {
+		t.Errorf("expected every injected fault to be logged, but got %d logs for %d faults", len(logs), failed)
+	}
+	if len(logs) > 0 && !strings.Contains(logs[0], "with seed 99") {
+		t.Errorf("expected log to contain the seed, but got: %s", logs[0])
+	}
+}
+
+func TestFaultInjectionOptionFailsCalls(t *testing.T) {
+	t.Parallel()
+	injected := errors.New("injected")
+	faults := NewFaults(1).
+		Fail(CallQuery, 1, injected).
+		Logger(func(string, ...interface{}) {})
+	db, mock, err := New(FaultInjectionOption(faults))
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT id FROM users").WillReturnRows(NewRows([]string{"id"}).AddRow(1))
+
+	_, err = db.Query("SELECT id FROM users")
+	if err != injected {
+		t.Errorf("expected injected error, but got: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err == nil {
+		t.Error("expected query expectation not to be consumed by the injected fault")
+	}
+}
+
+func TestFaultInjectionOptionFailsRowsAndResult(t *testing.T) {
+	t.Parallel()
+	injected := errors.New("injected")
+	faults := NewFaults(1).
+		FailRows(1, injected).
+		FailResult(1, injected).
+		Logger(func(string, ...interface{}) {})
+	db, mock, err := New(FaultInjectionOption(faults))
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT id FROM users").WillReturnRows(NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectExec("DELETE FROM users").WillReturnResult(NewResult(0, 1))
+
+	rows, err := db.Query("SELECT id FROM users")
+	if err != nil {
+		t.Fatalf("error was not expected, but got: %v", err)
+	}
+	if rows.Next() {
+		t.Error("row was not expected, since rows should fail")
+	}
+	if err := rows.Err(); err != injected {
+		t.Errorf("expected injected rows error, but got: %v", err)
+	}
+	rows.Close()
+
+	res, err := db.Exec("DELETE FROM users")
+	if err != nil {
+		t.Fatalf("error was not expected, but got: %v", err)
+	}
+	if _, err := res.RowsAffected(); err != injected {
+		t.Errorf("expected injected result error, but got: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestInjectedBadConnectionIsNotClosingTheDatabase(t *testing.T) {
+	t.Parallel()
+	faults := NewFaults(1).
+		Fail(CallExec, 1).
+		Logger(func(string, ...interface{}) {})
+	db, mock, err := New(FaultInjectionOption(faults))
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	if _, err := db.Exec("DELETE FROM users"); err != driver.ErrBadConn {
+		t.Errorf("expected injected driver.ErrBadConn, but got: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expected the discarded connections not to be rejected closes, but got: %s", err)
+	}
+}
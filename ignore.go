@@ -0,0 +1,135 @@
+package sqlmock
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"regexp"
+)
+
+// IgnoredStatement describes statements, which the mock answers
+// without matching expectations, such as the boilerplate queries
+// drivers and ORMs issue on connect. Ignored statements are skipped
+// by the ordered matcher and recorded only in the call journal.
+type IgnoredStatement struct {
+	// Pattern is a regular expression matched against the statement
+	Pattern string
+	// Rows answer ignored queries, no rows are returned if nil
+	Rows *Rows
+	// Result answers ignored execs, no rows are affected if nil
+	Result driver.Result
+}
+
+// ignoreRule is an ignored statement with its pattern compiled
+type ignoreRule struct {
+	re     *regexp.Regexp
+	rows   *Rows
+	result driver.Result
+}
+
+// IgnoreStatementsOption makes the mock answer the statements matching
+// any of the patterns with canned responses, before expectations are
+// matched. Presets for common bootstrap statements are returned by
+// MySQLBootstrap, PostgresBootstrap and SQLiteBootstrap.
+func IgnoreStatementsOption(statements ...IgnoredStatement) func(*sqlmock) error {
+	return func(s *sqlmock) error {
+		for _, st := range statements {
+			re, err := regexp.Compile(st.Pattern)
+			if err != nil {
+				return fmt.Errorf("invalid ignored statement pattern: %s", err)
+			}
+			s.ignored = append(s.ignored, ignoreRule{re: re, rows: st.Rows, result: st.Result})
+		}
+		return nil
+	}
+}
+
+// IgnoreCallsOption makes the mock answer all calls of the given
+// kinds, such as CallPing or CallClose, without expectations. Ignored
+// queries return no rows and ignored execs affect no rows.
+func IgnoreCallsOption(kinds ...CallKind) func(*sqlmock) error {
+	return func(s *sqlmock) error {
+		if s.ignoredKinds == nil {
+			s.ignoredKinds = make(map[CallKind]bool)
+		}
+		for _, kind := range kinds {
+			s.ignoredKinds[kind] = true
+		}
+		return nil
+	}
+}
+
+// MySQLBootstrap returns the statements MySQL drivers and ORMs
+// commonly issue on connect, to be used with IgnoreStatementsOption
+func MySQLBootstrap() []IgnoredStatement {
+	return []IgnoredStatement{
+		{Pattern: `(?i)^SELECT\s+VERSION\(\)`, Rows: NewRows([]string{"VERSION()"}).AddRow("8.0.36")},
+		{Pattern: `(?i)^SELECT\s+@@`, Rows: NewRows([]string{"@@value"}).AddRow("")},
+		{Pattern: `(?i)^SET\s+(NAMES|SESSION|@@|sql_mode|time_zone|autocommit)`},
+		{Pattern: `(?i)^SHOW\s+(VARIABLES|WARNINGS)`, Rows: NewRows([]string{"Variable_name", "Value"})},
+	}
+}
+
+// PostgresBootstrap returns the statements PostgreSQL drivers and
+// ORMs commonly issue on connect, to be used with IgnoreStatementsOption
+func PostgresBootstrap() []IgnoredStatement {
+	return []IgnoredStatement{
+		{Pattern: `(?i)^SELECT\s+version\(\)`, Rows: NewRows([]string{"version"}).AddRow("PostgreSQL 16.2")},
+		{Pattern: `(?i)^SELECT\s+current_(schema|database)\(\)`, Rows: NewRows([]string{"current"}).AddRow("public")},
+		{Pattern: `(?i)^SHOW\s+search_path`, Rows: NewRows([]string{"search_path"}).AddRow(`"$user", public`)},
+		{Pattern: `(?i)^SHOW\s+server_version`, Rows: NewRows([]string{"server_version"}).AddRow("16.2")},
+		{Pattern: `(?i)^SET\s+(search_path|TIME\s+ZONE|application_name|client_encoding|statement_timeout)`},
+	}
+}
+
+// SQLiteBootstrap returns the statements SQLite drivers and ORMs
+// commonly issue on connect, to be used with IgnoreStatementsOption
+func SQLiteBootstrap() []IgnoredStatement {
+	return []IgnoredStatement{
+		{Pattern: `(?i)^SELECT\s+sqlite_version\(\)`, Rows: NewRows([]string{"sqlite_version()"}).AddRow("3.45.1")},
+		{Pattern: `(?i)^PRAGMA\s+`, Rows: NewRows([]string{"value"})},
+	}
+}
+
+// ignoredKind returns whether calls of the kind are ignored and
+// records them in the journal if they are, c.mu must be held
+func (c *sqlmock) ignoredKind(kind CallKind) bool {
+	if !c.ignoredKinds[kind] {
+		return false
+	}
+	c.record(kind, "", nil, CallIgnored)
+	return true
+}
+
+// ignoredStatement returns the rule, which ignores the statement
+// and records the call in the journal, c.mu must be held
+func (c *sqlmock) ignoredStatement(kind CallKind, query string, args []driver.Value) (*ignoreRule, bool) {
+	if c.ignoredKinds[kind] {
+		c.record(kind, query, args, CallIgnored)
+		return &ignoreRule{}, true
+	}
+	stripped := stripQuery(query)
+	for i := range c.ignored {
+		if c.ignored[i].re.MatchString(stripped) {
+			c.record(kind, query, args, CallIgnored)
+			return &c.ignored[i], true
+		}
+	}
+	return nil, false
+}
+
+// answerRows returns the rows of an ignored query
+func (r *ignoreRule) answerRows(ex *ExpectedQuery) driver.Rows {
+	rows := r.rows
+	if rows == nil {
+		rows = NewRows(nil)
+	}
+	return (&rowSets{sets: []*Rows{rows}}).clone(ex)
+}
+
+// answerResult returns the result of an ignored exec
+func (r *ignoreRule) answerResult() driver.Result {
+	if r.result == nil {
+		return NewResult(0, 0)
+	}
+	return r.result
+}
@@ -0,0 +1,131 @@
+// +build go1.8
+
+package sqlmock
+
+import (
+	"context"
+	"testing"
+)
+
+func TestIgnoredStatementsAreSkippedInOrder(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New(IgnoreStatementsOption(MySQLBootstrap()...))
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectExec("UPDATE users").WillReturnResult(NewResult(0, 1))
+
+	if _, err := db.Exec("SET NAMES utf8mb4"); err != nil {
+		t.Fatalf("unexpected error on ignored exec: %s", err)
+	}
+	var version string
+	if err := db.QueryRow("SELECT VERSION()").Scan(&version); err != nil {
+		t.Fatalf("unexpected error on ignored query: %s", err)
+	}
+	if version != "8.0.36" {
+		t.Errorf("expected the canned version, but got: %s", version)
+	}
+	if _, err := db.Exec("UPDATE users SET active = 1"); err != nil {
+		t.Errorf("unexpected error on update: %s", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+
+	calls := mock.Calls()
+	if len(calls) != 3 {
+		t.Fatalf("expected 3 calls in the journal, but got: %+v", calls)
+	}
+	for i, call := range calls[:2] {
+		if call.Outcome != CallIgnored {
+			t.Errorf("expected call %d to be ignored, but got: %+v", i, call)
+		}
+	}
+	if calls[2].Outcome != CallMatched {
+		t.Errorf("expected the update to be matched, but got: %+v", calls[2])
+	}
+}
+
+func TestIgnoredStatementCannedResponses(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New(IgnoreStatementsOption(
+		IgnoredStatement{Pattern: `^SELECT 1$`},
+		IgnoredStatement{Pattern: `^DELETE FROM sessions`, Result: NewResult(0, 3)},
+	))
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	rows, err := db.Query("SELECT 1")
+	if err != nil {
+		t.Fatalf("unexpected error on ignored query: %s", err)
+	}
+	if rows.Next() {
+		t.Errorf("expected no rows for an ignored query without canned rows")
+	}
+	rows.Close()
+
+	res, err := db.Exec("DELETE FROM sessions WHERE expired")
+	if err != nil {
+		t.Fatalf("unexpected error on ignored exec: %s", err)
+	}
+	if n, _ := res.RowsAffected(); n != 3 {
+		t.Errorf("expected the canned result to affect 3 rows, but got: %d", n)
+	}
+
+	stmt, err := db.Prepare("DELETE FROM sessions WHERE id = ?")
+	if err != nil {
+		t.Fatalf("unexpected error on ignored prepare: %s", err)
+	}
+	if _, err := stmt.Exec(1); err != nil {
+		t.Errorf("unexpected error on ignored statement exec: %s", err)
+	}
+	stmt.Close()
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestIgnoredCallKinds(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New(MonitorPingsOption(true), IgnoreCallsOption(CallPing, CallClose))
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+
+	if err := db.PingContext(context.Background()); err != nil {
+		t.Errorf("unexpected error on ignored ping: %s", err)
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("unexpected error on begin: %s", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Errorf("unexpected error on commit: %s", err)
+	}
+	if err := db.Close(); err != nil {
+		t.Errorf("unexpected error on ignored close: %s", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+
+	calls := mock.Calls()
+	if len(calls) == 0 || calls[0].Kind != CallPing || calls[0].Outcome != CallIgnored {
+		t.Errorf("expected the ping to be ignored first, but got: %+v", calls)
+	}
+}
+
+func TestIgnoreStatementsOptionInvalidPattern(t *testing.T) {
+	t.Parallel()
+	if _, _, err := New(IgnoreStatementsOption(IgnoredStatement{Pattern: "SELECT ("})); err == nil {
+		t.Errorf("expected an error for an invalid ignored statement pattern")
+	}
+}
@@ -0,0 +1,94 @@
+package sqlmock
+
+import (
+	"database/sql/driver"
+	"fmt"
+)
+
+// CallOutcome tells how the mock answered a call
+type CallOutcome int
+
+// outcomes of the calls recorded in the call journal
+const (
+	// CallMatched is a call matched by an expectation
+	CallMatched CallOutcome = iota
+	// CallStubbed is a call answered by a stub, see StubQuery and StubExec
+	CallStubbed
+	// CallIgnored is a call answered without expectations, see
+	// IgnoreStatementsOption and IgnoreCallsOption
+	CallIgnored
+	// CallRejected is a call, which was not expected or did not match
+	// the expectation, it fails ExpectationsWereMet, unless allowed
+	// with AllowRejectedCallsOption
+	CallRejected
+)
+
+var callOutcomeNames = map[CallOutcome]string{
+	CallMatched:  "matched",
+	CallStubbed:  "stubbed",
+	CallIgnored:  "ignored",
+	CallRejected: "rejected",
+}
+
+// String returns the name of the outcome
+func (o CallOutcome) String() string {
+	if name, ok := callOutcomeNames[o]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+// Call is a database driver call received by the mock,
+// as recorded in the call journal
+type Call struct {
+	Kind CallKind
+	// Query is the SQL of Prepare, Query and Exec calls
+	Query string
+	// Args are the arguments of Query and Exec calls
+	Args    []driver.Value
+	Outcome CallOutcome
+	// Err is the error a rejected call was answered with
+	Err error
+}
+
+// Calls returns the journal of the calls received by
+// the mock, in the order they were received
+func (c *sqlmock) Calls() []Call {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	calls := make([]Call, len(c.journal))
+	copy(calls, c.journal)
+	return calls
+}
+
+// record appends a call to the journal, c.mu must be held
+func (c *sqlmock) record(kind CallKind, query string, args []driver.Value, outcome CallOutcome) {
+	c.journal = append(c.journal, Call{Kind: kind, Query: query, Args: args, Outcome: outcome})
+	c.notify()
+}
+
+// reject records a call, which was answered with an error since
+// no expectation matched it, and returns the error, c.mu must be held
+func (c *sqlmock) reject(kind CallKind, query string, args []driver.Value, err error) error {
+	c.journal = append(c.journal, Call{Kind: kind, Query: query, Args: args, Outcome: CallRejected, Err: err})
+	c.notify()
+	return err
+}
+
+// rejected returns an error listing the rejected calls, so they fail
+// the test even if the code under test did not check the error
+func (c *sqlmock) rejected() error {
+	var msg string
+	var n int
+	for _, call := range c.journal {
+		if call.Outcome == CallRejected {
+			msg += "\n  - " + call.Err.Error()
+			n++
+		}
+	}
+	if n == 0 {
+		return nil
+	}
+	return fmt.Errorf("there were %d calls, which were rejected by the mock:%s", n, msg)
+}
@@ -0,0 +1,90 @@
+package sqlmock
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRejectedCallFailsExpectationsWereMet(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	// the error is swallowed, as by a best effort audit insert
+	_, _ = db.Exec("INSERT INTO audit VALUES (1)")
+
+	err = mock.ExpectationsWereMet()
+	if err == nil || !strings.Contains(err.Error(), "INSERT INTO audit") {
+		t.Fatalf("expected the rejected insert to fail expectations, but got: %v", err)
+	}
+
+	calls := mock.Calls()
+	if len(calls) != 1 || calls[0].Outcome != CallRejected || calls[0].Err == nil {
+		t.Errorf("expected a rejected call in the journal, but got: %+v", calls)
+	}
+}
+
+func TestAllowRejectedCallsOption(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New(AllowRejectedCallsOption(true))
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	if _, err := db.Begin(); err == nil {
+		t.Fatal("expected an error, begin was not expected")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+	if calls := mock.Calls(); len(calls) != 1 || calls[0].Kind != CallBegin || calls[0].Outcome != CallRejected {
+		t.Errorf("expected the rejected begin in the journal, but got: %+v", calls)
+	}
+}
+
+func TestPoolClosesAreNotRejected(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	mock.MatchExpectationsInOrder(false)
+
+	const workers = 4
+	for i := 0; i < workers; i++ {
+		mock.ExpectQuery("SELECT id FROM users").
+			WillDelayFor(10 * time.Millisecond).
+			WillReturnRows(NewRows([]string{"id"}).AddRow(1))
+	}
+	mock.ExpectClose()
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var id int
+			if err := db.QueryRow("SELECT id FROM users").Scan(&id); err != nil {
+				t.Errorf("unexpected error on query: %s", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if err := mock.ExpectationsWereMet(); err == nil || !strings.Contains(err.Error(), "ExpectedClose") {
+		t.Errorf("expected only the close to be pending, but got: %v", err)
+	}
+
+	if err := db.Close(); err != nil {
+		t.Fatalf("an error '%s' was not expected when closing the database", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
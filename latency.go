@@ -0,0 +1,137 @@
+package sqlmock
+
+import (
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Latency is a mock level model of the time database calls take.
+// It has a base delay per call kind and a seeded random jitter, which
+// is added to every delayed call. The latency is applied to calls which
+// expectation has no delay set with WillDelayFor, so an expectation
+// delay overrides the model, WillDelayFor(0) included.
+//
+// Given the same seed and the same order of calls, the delays
+// are the same, which makes failures reproducible.
+type Latency struct {
+	mu     sync.Mutex
+	rnd    *rand.Rand
+	base   map[CallKind]time.Duration
+	jitter Distribution
+}
+
+// NewLatency creates a latency model which
+// samples jitter from the given seed
+func NewLatency(seed int64) *Latency {
+	return &Latency{
+		rnd:  rand.New(rand.NewSource(seed)),
+		base: make(map[CallKind]time.Duration),
+	}
+}
+
+// Base sets the default delay for the given call kind
+func (l *Latency) Base(kind CallKind, delay time.Duration) *Latency {
+	l.base[kind] = delay
+	return l
+}
+
+// Jitter sets the distribution of the random
+// delay added to the base delay of every call
+func (l *Latency) Jitter(dist Distribution) *Latency {
+	l.jitter = dist
+	return l
+}
+
+// delay samples the delay for a call of the given kind
+func (l *Latency) delay(kind CallKind) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	d := l.base[kind]
+	if l.jitter != nil {
+		d += l.jitter.Sample(l.rnd)
+	}
+	if d < 0 {
+		return 0
+	}
+	return d
+}
+
+// Distribution samples random durations,
+// it is used as a Latency jitter
+type Distribution interface {
+	Sample(r *rand.Rand) time.Duration
+}
+
+// DistributionFunc type is an adapter to allow the use of
+// ordinary functions as Distribution.
+type DistributionFunc func(r *rand.Rand) time.Duration
+
+// Sample implements the Distribution
+func (f DistributionFunc) Sample(r *rand.Rand) time.Duration {
+	return f(r)
+}
+
+// UniformJitter returns a Distribution of durations
+// spread evenly between min and max
+func UniformJitter(min, max time.Duration) Distribution {
+	return DistributionFunc(func(r *rand.Rand) time.Duration {
+		if max <= min {
+			return min
+		}
+		return min + time.Duration(r.Int63n(int64(max-min)))
+	})
+}
+
+// NormalJitter returns a Distribution of normally
+// distributed durations with the given mean and
+// standard deviation
+func NormalJitter(mean, stddev time.Duration) Distribution {
+	return DistributionFunc(func(r *rand.Rand) time.Duration {
+		return mean + time.Duration(r.NormFloat64()*float64(stddev))
+	})
+}
+
+// PercentileJitter returns a Distribution defined by a percentile
+// table, for example {50: time.Millisecond, 99: 20 * time.Millisecond}
+// means that half of the samples are below one millisecond and one
+// percent is above twenty. Durations between the given percentiles are
+// interpolated linearly, starting from zero at percentile zero, and the
+// samples above the highest percentile extrapolate its last interval.
+func PercentileJitter(table map[float64]time.Duration) Distribution {
+	percentiles := make([]float64, 0, len(table))
+	for p := range table {
+		percentiles = append(percentiles, p)
+	}
+	sort.Float64s(percentiles)
+
+	return DistributionFunc(func(r *rand.Rand) time.Duration {
+		u := r.Float64() * 100
+		var prevP, lastP float64
+		var prevD, lastD time.Duration
+		for _, p := range percentiles {
+			d := table[p]
+			if u <= p {
+				if p == prevP {
+					return d
+				}
+				return interpolate(u, prevP, p, prevD, d)
+			}
+			lastP, lastD = prevP, prevD
+			prevP, prevD = p, d
+		}
+		if prevP == lastP {
+			return prevD
+		}
+		return interpolate(u, lastP, prevP, lastD, prevD)
+	})
+}
+
+// interpolate returns the duration at percentile u on the line through
+// the durations from and to at the percentiles p0 and p1
+func interpolate(u, p0, p1 float64, from, to time.Duration) time.Duration {
+	ratio := (u - p0) / (p1 - p0)
+	return from + time.Duration(ratio*float64(to-from))
+}
@@ -0,0 +1,169 @@
+// +build go1.8
+
+package sqlmock
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestLatencyIsReproducible(t *testing.T) {
+	t.Parallel()
+	a := NewLatency(42).Base(CallQuery, time.Millisecond).Jitter(NormalJitter(0, time.Millisecond))
+	b := NewLatency(42).Base(CallQuery, time.Millisecond).Jitter(NormalJitter(0, time.Millisecond))
+
+	for i := 0; i < 100; i++ {
+		da, db := a.delay(CallQuery), b.delay(CallQuery)
+		if da != db {
+			t.Fatalf("expected the same delay for the same seed, but got %s and %s", da, db)
+		}
+		if da < 0 {
+			t.Fatalf("expected a non negative delay, but got %s", da)
+		}
+	}
+}
+
+func TestLatencyBasePerCallKind(t *testing.T) {
+	t.Parallel()
+	l := NewLatency(1).Base(CallExec, time.Second)
+
+	if d := l.delay(CallExec); d != time.Second {
+		t.Errorf("expected exec delay of 1s, but got %s", d)
+	}
+	if d := l.delay(CallQuery); d != 0 {
+		t.Errorf("expected no query delay, but got %s", d)
+	}
+}
+
+func TestJitterDistributions(t *testing.T) {
+	t.Parallel()
+	r := rand.New(rand.NewSource(7))
+
+	uniform := UniformJitter(time.Millisecond, 2*time.Millisecond)
+	percentile := PercentileJitter(map[float64]time.Duration{
+		50:  time.Millisecond,
+		90:  5 * time.Millisecond,
+		100: 20 * time.Millisecond,
+	})
+
+	var belowMedian int
+	for i := 0; i < 1000; i++ {
+		if d := uniform.Sample(r); d < time.Millisecond || d >= 2*time.Millisecond {
+			t.Fatalf("uniform sample %s is out of range", d)
+		}
+
+		d := percentile.Sample(r)
+		if d < 0 || d > 20*time.Millisecond {
+			t.Fatalf("percentile sample %s is out of range", d)
+		}
+		if d <= time.Millisecond {
+			belowMedian++
+		}
+	}
+
+	if belowMedian < 400 || belowMedian > 600 {
+		t.Errorf("expected about half of percentile samples below median, but got %d of 1000", belowMedian)
+	}
+}
+
+func TestPercentileJitterTail(t *testing.T) {
+	t.Parallel()
+	r := rand.New(rand.NewSource(7))
+
+	percentile := PercentileJitter(map[float64]time.Duration{
+		50: time.Millisecond,
+		99: 20 * time.Millisecond,
+	})
+
+	var aboveTail int
+	for i := 0; i < 10000; i++ {
+		if d := percentile.Sample(r); d > 20*time.Millisecond {
+			aboveTail++
+		}
+	}
+
+	if aboveTail < 50 || aboveTail > 150 {
+		t.Errorf("expected about one percent of samples above the 99th percentile, but got %d of 10000", aboveTail)
+	}
+}
+
+func TestLatencyOptionDelaysCalls(t *testing.T) {
+	t.Parallel()
+	clock := NewFakeClock(time.Now())
+	latency := NewLatency(1).Base(CallExec, time.Minute)
+	db, mock, err := New(ClockOption(clock), LatencyOption(latency))
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectExec("UPDATE users").WillReturnResult(NewResult(0, 1))
+	mock.ExpectExec("UPDATE users").WillDelayFor(time.Hour).WillReturnResult(NewResult(0, 1))
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := db.Exec("UPDATE users SET name = 'john'")
+		done <- err
+	}()
+
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+	if err := <-done; err != nil {
+		t.Errorf("error was not expected, but got: %v", err)
+	}
+
+	go func() {
+		_, err := db.Exec("UPDATE users SET name = 'jane'")
+		done <- err
+	}()
+
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+	select {
+	case <-done:
+		t.Fatal("expectation delay was expected to override the latency")
+	default:
+	}
+
+	clock.Advance(time.Hour)
+	if err := <-done; err != nil {
+		t.Errorf("error was not expected, but got: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestZeroDelayOverridesLatency(t *testing.T) {
+	t.Parallel()
+	clock := NewFakeClock(time.Now())
+	latency := NewLatency(1).Base(CallExec, time.Minute)
+	db, mock, err := New(ClockOption(clock), LatencyOption(latency))
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectExec("UPDATE users").WillDelayFor(0).WillReturnResult(NewResult(0, 1))
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := db.Exec("UPDATE users SET name = 'john'")
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("error was not expected, but got: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the zero expectation delay to override the latency")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
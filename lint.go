@@ -0,0 +1,250 @@
+package sqlmock
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+)
+
+// LintWarning describes a queued expectation, which is likely
+// a mistake in the test, as reported by Sqlmock.Lint
+type LintWarning struct {
+	// Declared is the file and line which registered the expectation
+	Declared string
+	// Expectation is the string representation of the expectation
+	Expectation string
+	// Message explains the problem
+	Message string
+}
+
+// String returns the warning prefixed with the declaring line
+func (w LintWarning) String() string {
+	return w.Declared + ": " + w.Message
+}
+
+// lintEntry is a snapshot of a pending expectation, taken under its
+// lock, so that pairs of expectations may be compared without locks
+type lintEntry struct {
+	kind     CallKind
+	declared string
+	str      string
+	sql      string
+	hasSQL   bool
+	args     []driver.Value
+	invalid  error
+	failing  bool
+}
+
+// Lint analyzes the pending expectations and returns warnings for those
+// which are likely a mistake: expectations shadowed by a broader one
+// registered before them, duplicates, prepared statements which are never
+// used, conflicting arguments for the same SQL and, in ordered mode,
+// expectations which may never be reached.
+func (c *sqlmock) Lint() []LintWarning {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lint()
+}
+
+// lint runs the analysis, c.mu must be held
+func (c *sqlmock) lint() []LintWarning {
+	var entries []lintEntry
+	for _, e := range c.expected {
+		e.Lock()
+		if !e.fulfilled() {
+			entries = append(entries, c.snapshot(e))
+		}
+		e.Unlock()
+	}
+
+	var warnings []LintWarning
+	warn := func(e lintEntry, format string, args ...interface{}) {
+		warnings = append(warnings, LintWarning{
+			Declared:    e.declared,
+			Expectation: e.str,
+			Message:     fmt.Sprintf(format, args...),
+		})
+	}
+
+	for i, e := range entries {
+		if c.ordered && e.invalid != nil {
+			warn(e, "expectation may never be matched: %s", e.invalid)
+			for _, dead := range entries[i+1:] {
+				warn(dead, "expectation may never be reached in ordered mode, the expectation declared at %s may never be matched", e.declared)
+			}
+			break
+		}
+	}
+
+	for i, e := range entries {
+		if !e.hasSQL {
+			continue
+		}
+		for _, prev := range entries[:i] {
+			if !prev.hasSQL || prev.kind != e.kind {
+				continue
+			}
+			conflict := -1
+			if prev.sql == e.sql {
+				conflict = c.argConflict(prev.args, e.args)
+			}
+			switch {
+			case prev.sql == e.sql && reflect.DeepEqual(prev.args, e.args):
+				warn(e, "duplicates the expectation declared at %s, calls are matched in the order of registration", prev.declared)
+			case prev.sql == e.sql && prev.args != nil && e.args != nil && len(prev.args) != len(e.args):
+				warn(e, "expects %d arguments, but the expectation declared at %s of the same sql expects %d", len(e.args), prev.declared, len(prev.args))
+			case !c.ordered && c.covers(prev, e):
+				warn(e, "is shadowed by the broader expectation declared at %s, which matches its calls first", prev.declared)
+			case conflict >= 0:
+				warn(e, "expects argument %d as %s, but the expectation declared at %s of the same sql expects %s", conflict+1, describeArg(e.args[conflict]), prev.declared, describeArg(prev.args[conflict]))
+			default:
+				continue
+			}
+			break
+		}
+	}
+
+	for i, e := range entries {
+		if e.kind != CallPrepare || e.failing {
+			continue
+		}
+		used := false
+		for _, stmt := range entries[i+1:] {
+			if (stmt.kind == CallQuery || stmt.kind == CallExec) && (stmt.sql == e.sql || c.sqlCovers(e.sql, stmt.sql) || c.sqlCovers(stmt.sql, e.sql)) {
+				used = true
+				break
+			}
+		}
+		if !used {
+			warn(e, "prepared statement is never used, there is no query or exec expected for it")
+		}
+	}
+	return warnings
+}
+
+// snapshot copies what lint needs, the expectation must be locked
+func (c *sqlmock) snapshot(e expectation) lintEntry {
+	entry := lintEntry{
+		kind:     e.kind(),
+		declared: e.common().declared,
+		str:      e.String(),
+		invalid:  e.common().invalid,
+		failing:  e.common().err != nil,
+	}
+	entry.sql, entry.hasSQL = expectedSQL(e)
+
+	var qe *queryBasedExpectation
+	switch e := e.(type) {
+	case *ExpectedQuery:
+		qe = &e.queryBasedExpectation
+	case *ExpectedExec:
+		qe = &e.queryBasedExpectation
+	}
+	if qe != nil {
+		entry.args = qe.args
+		if entry.invalid == nil {
+			entry.invalid = c.validateArgs(qe)
+		}
+	}
+	return entry
+}
+
+// covers returns whether all calls matched by b are matched by a as well
+func (c *sqlmock) covers(a, b lintEntry) bool {
+	if !c.sqlCovers(a.sql, b.sql) {
+		return false
+	}
+	if a.args == nil {
+		return true
+	}
+	if len(a.args) != len(b.args) {
+		return false
+	}
+	for i, arg := range a.args {
+		if _, isAny := arg.(anyArgument); isAny {
+			continue
+		}
+		matcher, ok := arg.(Argument)
+		if _, dynamic := b.args[i].(Argument); dynamic {
+			return false
+		}
+		if (ok && !matcher.Match(b.args[i])) || (!ok && !reflect.DeepEqual(arg, b.args[i])) {
+			return false
+		}
+	}
+	return true
+}
+
+// argConflict returns the position of the first argument, at which
+// the expected arguments of the same count conflict, or -1. Literals
+// conflict when they are converted to different types, a literal and
+// an Argument when the matcher rejects the literal. Literals of the
+// same type, but other values, are expected by different calls.
+func (c *sqlmock) argConflict(a, b []driver.Value) int {
+	if a == nil || b == nil || len(a) != len(b) {
+		return -1
+	}
+	for k := range a {
+		x, xok := c.literalArg(a[k])
+		y, yok := c.literalArg(b[k])
+		switch {
+		case xok && yok:
+			if reflect.TypeOf(x) != reflect.TypeOf(y) {
+				return k
+			}
+		case xok:
+			if matcher, ok := b[k].(Argument); ok && !matcher.Match(x) {
+				return k
+			}
+		case yok:
+			if matcher, ok := a[k].(Argument); ok && !matcher.Match(y) {
+				return k
+			}
+		}
+	}
+	return -1
+}
+
+// literalArg returns the driver value of an expected argument, which
+// is not an Argument matcher, as it is compared with the actual one
+func (c *sqlmock) literalArg(arg driver.Value) (driver.Value, bool) {
+	if _, ok := arg.(Argument); ok {
+		return nil, false
+	}
+	v, err := c.converter.ConvertValue(arg)
+	if err != nil {
+		return nil, false
+	}
+	return v, true
+}
+
+// describeArg describes an expected argument in a lint warning
+func describeArg(arg driver.Value) string {
+	if _, ok := arg.(Argument); ok {
+		return fmt.Sprintf("matcher %T", arg)
+	}
+	return fmt.Sprintf("%T %v", arg, arg)
+}
+
+// sqlCovers returns whether the expected SQL a matches the SQL b
+// is expected to match, regular expressions are compared with the
+// text of the other expression
+func (c *sqlmock) sqlCovers(a, b string) bool {
+	if _, ok := c.queryMatcher.(regexpMatcher); ok {
+		b = unescape(b)
+	}
+	return c.queryMatcher.Match(a, b) == nil
+}
+
+// unescape removes backslashes from escaped characters of a regexp
+func unescape(expr string) string {
+	var b bytes.Buffer
+	for i := 0; i < len(expr); i++ {
+		if expr[i] == '\\' && i+1 < len(expr) {
+			i++
+		}
+		b.WriteByte(expr[i])
+	}
+	return b.String()
+}
@@ -0,0 +1,173 @@
+package sqlmock
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func lintMessages(warnings []LintWarning) string {
+	var msgs []string
+	for _, w := range warnings {
+		msgs = append(msgs, w.String())
+	}
+	return strings.Join(msgs, "\n")
+}
+
+func TestLintShadowedExpectation(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+	mock.MatchExpectationsInOrder(false)
+
+	rows := NewRows([]string{"id"})
+	mock.ExpectQuery("SELECT").WillReturnRows(rows)
+	mock.ExpectQuery(`SELECT id FROM users WHERE id = \?`).WithArgs(1).WillReturnRows(rows)
+	mock.ExpectQuery(`SELECT name FROM users WHERE id = \?`).WithArgs(AnyArg()).WillReturnRows(rows)
+	mock.ExpectQuery(`SELECT name FROM users WHERE id = \?`).WithArgs(2).WillReturnRows(rows)
+
+	warnings := mock.Lint()
+	if len(warnings) != 3 {
+		t.Fatalf("expected 3 warnings, but got:\n%s", lintMessages(warnings))
+	}
+	for _, w := range warnings {
+		if !strings.Contains(w.Message, "is shadowed by the broader expectation declared at lint_test.go:") {
+			t.Errorf("expected a shadowing warning, but got: %s", w)
+		}
+		if !strings.HasPrefix(w.Declared, "lint_test.go:") {
+			t.Errorf("expected the warning to point at the declaring line, but got: %s", w.Declared)
+		}
+	}
+}
+
+func TestLintDuplicatesAndConflictingArgs(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectExec("DELETE FROM users").WithArgs(1).WillReturnResult(NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM users").WithArgs(1).WillReturnResult(NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM users").WithArgs(1, 2).WillReturnResult(NewResult(0, 1))
+
+	msgs := lintMessages(mock.Lint())
+	if !strings.Contains(msgs, "duplicates the expectation declared at") {
+		t.Errorf("expected a duplicate warning, but got:\n%s", msgs)
+	}
+	if !strings.Contains(msgs, "expects 2 arguments, but the expectation declared at") {
+		t.Errorf("expected a conflicting arguments warning, but got:\n%s", msgs)
+	}
+	if strings.Contains(msgs, "shadowed") {
+		t.Errorf("expected no shadowing warning in ordered mode, but got:\n%s", msgs)
+	}
+}
+
+func TestLintConflictingArgsOfSameCount(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectExec("UPDATE users SET name").WithArgs("john", 1).WillReturnResult(NewResult(0, 1))
+	mock.ExpectExec("UPDATE users SET name").WithArgs("jane", 2).WillReturnResult(NewResult(0, 1))
+	mock.ExpectExec("UPDATE users SET name").WithArgs("jane", "2").WillReturnResult(NewResult(0, 1))
+	mock.ExpectExec("UPDATE users SET updated_at").WithArgs(AnyTime{}).WillReturnResult(NewResult(0, 1))
+	mock.ExpectExec("UPDATE users SET updated_at").WithArgs("yesterday").WillReturnResult(NewResult(0, 1))
+
+	warnings := mock.Lint()
+	if len(warnings) != 2 {
+		t.Fatalf("expected 2 warnings, but got:\n%s", lintMessages(warnings))
+	}
+	if !strings.Contains(warnings[0].Message, "expects argument 2 as string 2, but the expectation declared at") {
+		t.Errorf("expected a conflict of literal types, but got: %s", warnings[0])
+	}
+	if !strings.Contains(warnings[1].Message, "expects argument 1 as string yesterday") ||
+		!strings.Contains(warnings[1].Message, "expects matcher sqlmock.AnyTime") {
+		t.Errorf("expected a literal rejected by a matcher, but got: %s", warnings[1])
+	}
+}
+
+func TestLintUnusedPreparedStatement(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectPrepare("INSERT INTO users").ExpectExec().WillReturnResult(NewResult(1, 1))
+	mock.ExpectPrepare("INSERT INTO logs")
+	mock.ExpectPrepare("INSERT INTO audit").WillReturnError(fmt.Errorf("audit is read only"))
+
+	warnings := mock.Lint()
+	if len(warnings) != 1 || !strings.Contains(warnings[0].Message, "prepared statement is never used") {
+		t.Fatalf("expected an unused prepared statement warning, but got:\n%s", lintMessages(warnings))
+	}
+	if !strings.Contains(warnings[0].Expectation, "INSERT INTO logs") {
+		t.Errorf("expected the warning for the logs statement, but got: %s", warnings[0].Expectation)
+	}
+}
+
+func TestLintDeadExpectationsInOrder(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New(DialectOption(DialectMySQL))
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE users SET name = \? WHERE id = \?`).WithArgs("john").WillReturnResult(NewResult(0, 1))
+	mock.ExpectCommit()
+
+	warnings := mock.Lint()
+	if len(warnings) != 2 {
+		t.Fatalf("expected 2 warnings, but got:\n%s", lintMessages(warnings))
+	}
+	if !strings.Contains(warnings[0].Message, "may never be matched") {
+		t.Errorf("expected a never matched warning, but got: %s", warnings[0])
+	}
+	if !strings.Contains(warnings[1].Message, "may never be reached in ordered mode") {
+		t.Errorf("expected a dead expectation warning, but got: %s", warnings[1])
+	}
+}
+
+func TestLintOption(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New(LintOption(true))
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectPrepare("SELECT")
+	err = mock.ExpectationsWereMet()
+	if err == nil || !strings.Contains(err.Error(), "lint warnings") {
+		t.Errorf("expected lint warnings, but got: %v", err)
+	}
+}
+
+func TestLintOptionKeepsSQLInError(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New(LintOption(true), DialectOption(DialectMySQL))
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectExec(`UPDATE users SET name = \? WHERE note LIKE '%a%' AND id = \?`).WithArgs("john").WillReturnResult(NewResult(0, 1))
+	err = mock.ExpectationsWereMet()
+	if err == nil || !strings.Contains(err.Error(), "lint warnings") {
+		t.Fatalf("expected lint warnings, but got: %v", err)
+	}
+	if strings.Contains(err.Error(), "MISSING") || !strings.Contains(err.Error(), "LIKE '%a%'") {
+		t.Errorf("expected the warnings to show the expected SQL as is, but got: %s", err)
+	}
+}
@@ -0,0 +1,222 @@
+package sqlmock
+
+// normalizer is implemented by query matchers, which match
+// when both SQL strings are equal once normalized. Expectations
+// are then looked up by their normalized SQL.
+type normalizer interface {
+	normalize(sql string) string
+}
+
+// queue holds expectations of the same kind in the order of
+// registration, fulfilled ones are dropped once at the head
+type queue struct {
+	items []expectation
+}
+
+// first returns the first pending expectation which accepts
+// the call, it is returned locked. A nil accept accepts any.
+func (q *queue) first(accept func(expectation) bool) expectation {
+	q.trim()
+	for _, e := range q.items {
+		e.Lock()
+		if !e.fulfilled() && (accept == nil || accept(e)) {
+			return e
+		}
+		e.Unlock()
+	}
+	return nil
+}
+
+// pending calls fn with each pending expectation locked
+func (q *queue) pending(fn func(expectation)) {
+	q.trim()
+	for _, e := range q.items {
+		e.Lock()
+		if !e.fulfilled() {
+			fn(e)
+		}
+		e.Unlock()
+	}
+}
+
+// trim drops the fulfilled expectations at the head
+func (q *queue) trim() {
+	for len(q.items) > 0 {
+		e := q.items[0]
+		e.Lock()
+		if !e.fulfilled() {
+			e.Unlock()
+			break
+		}
+		e.Unlock()
+		q.items[0] = nil
+		q.items = q.items[1:]
+	}
+}
+
+// index keeps registered expectations by kind of call and, when
+// the query matcher supports it, by normalized SQL, so matching a
+// call does not scan expectations, which may not match it
+type index struct {
+	cursor int // expectations before the cursor are fulfilled
+	kinds  map[CallKind]*queue
+	sqls   map[CallKind]map[string]*queue
+}
+
+func (ix *index) add(e expectation, n normalizer) {
+	if ix.kinds == nil {
+		ix.kinds = make(map[CallKind]*queue)
+		ix.sqls = make(map[CallKind]map[string]*queue)
+	}
+
+	kind := e.kind()
+	q, ok := ix.kinds[kind]
+	if !ok {
+		q = &queue{}
+		ix.kinds[kind] = q
+	}
+	q.items = append(q.items, e)
+
+	sql, ok := expectedSQL(e)
+	if !ok || n == nil {
+		return
+	}
+	bySQL, ok := ix.sqls[kind]
+	if !ok {
+		bySQL = make(map[string]*queue)
+		ix.sqls[kind] = bySQL
+	}
+	key := n.normalize(sql)
+	if q, ok = bySQL[key]; !ok {
+		q = &queue{}
+		bySQL[key] = q
+	}
+	q.items = append(q.items, e)
+}
+
+// lookup returns the queue of expectations, which may match the call
+func (ix *index) lookup(kind CallKind, query string, n normalizer) *queue {
+	if bySQL, ok := ix.sqls[kind]; ok && n != nil {
+		if q, ok := bySQL[n.normalize(query)]; ok {
+			return q
+		}
+		return &queue{}
+	}
+	if q, ok := ix.kinds[kind]; ok {
+		return q
+	}
+	return &queue{}
+}
+
+// expectedSQL returns the SQL of expectations matched by query
+func expectedSQL(e expectation) (string, bool) {
+	switch e := e.(type) {
+	case *ExpectedQuery:
+		return e.expectSQL, true
+	case *ExpectedExec:
+		return e.expectSQL, true
+	case *ExpectedPrepare:
+		return e.expectSQL, true
+	}
+	return "", false
+}
+
+// next returns the first pending expectation in the order of
+// registration locked, or nil if all of them are fulfilled. A
+// sequence, which was triggered, does not block the expectations
+// registered after it, so other calls may be made between retries.
+// c.mu must be held.
+func (c *sqlmock) next() expectation {
+	for i := c.index.cursor; i < len(c.expected); i++ {
+		e := c.expected[i]
+		e.Lock()
+		switch {
+		case e.fulfilled():
+			if i == c.index.cursor {
+				c.index.cursor++
+			}
+		case !openSequence(e):
+			return e
+		}
+		e.Unlock()
+	}
+	return nil
+}
+
+// openSequence returns whether the expectation is a sequence, which
+// was triggered and has responses left, the expectation must be locked
+func openSequence(e expectation) bool {
+	seq, ok := e.(interface{ sequenced() *sequence })
+	return ok && e.common().triggered && !seq.sequenced().done()
+}
+
+// nextOfSequence returns the first triggered sequence of the kind,
+// which accepts the call, locked. A nil accept accepts any.
+// c.mu must be held.
+func (c *sqlmock) nextOfSequence(kind CallKind, accept func(expectation) bool) expectation {
+	for _, e := range c.expected[c.index.cursor:] {
+		if e.kind() != kind {
+			continue
+		}
+		e.Lock()
+		if openSequence(e) && (accept == nil || accept(e)) {
+			return e
+		}
+		e.Unlock()
+	}
+	return nil
+}
+
+// allFulfilled returns whether all expectations are fulfilled,
+// c.mu must be held.
+func (c *sqlmock) allFulfilled() bool {
+	next := c.next()
+	if next == nil {
+		return true
+	}
+	next.Unlock()
+	return false
+}
+
+// match looks up the expectation of a call, which is returned locked.
+// In ordered mode only a triggered sequence or the next pending
+// expectation may match, if the latter is of another kind, it is
+// returned locked as next instead. Otherwise
+// the first pending expectation of the kind which accepts the call is
+// matched. An expectation, which predecessors were not triggered yet,
+// is not matched and its violation is returned as an error, unless
+// another expectation matches. c.mu must be held.
+func (c *sqlmock) match(kind CallKind, query string, accept func(expectation) bool) (matched, next expectation, err error) {
+	if c.ordered {
+		if seq := c.nextOfSequence(kind, accept); seq != nil {
+			return seq, nil, nil
+		}
+		next = c.next()
+		if next != nil && next.kind() != kind {
+			return nil, next, nil
+		}
+		if next != nil {
+			if pred := c.predecessor(next); pred != nil {
+				err = c.violation(next, pred)
+				next.Unlock()
+				return nil, nil, err
+			}
+		}
+		return next, nil, nil
+	}
+
+	n, _ := c.queryMatcher.(normalizer)
+	q := c.index.lookup(kind, query, n)
+	matched = q.first(func(e expectation) bool {
+		return (accept == nil || accept(e)) && c.predecessor(e) == nil
+	})
+	if matched != nil {
+		return matched, nil, nil
+	}
+	if blocked := q.first(accept); blocked != nil {
+		err = c.violation(blocked, c.predecessor(blocked))
+		blocked.Unlock()
+		return nil, nil, err
+	}
+	return nil, nil, nil
+}
@@ -0,0 +1,143 @@
+package sqlmock
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestEqualMatcherLooksUpNormalizedSQL(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New(QueryMatcherOption(QueryMatcherEqual), AllowRejectedCallsOption(true))
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+	mock.MatchExpectationsInOrder(false)
+
+	mock.ExpectExec("UPDATE users SET name = ? WHERE id = ?").WithArgs("john", 1).WillReturnResult(NewResult(0, 1))
+	mock.ExpectExec("UPDATE users SET name = ? WHERE id = ?").WithArgs("jane", 2).WillReturnResult(NewResult(0, 2))
+	mock.ExpectExec("DELETE FROM users").WillReturnResult(NewResult(0, 3))
+
+	if _, err := db.Exec("DELETE   FROM\n\tusers"); err != nil {
+		t.Errorf("unexpected error on delete: %s", err)
+	}
+	res, err := db.Exec("UPDATE users\n SET name = ?\n WHERE id = ?", "jane", 2)
+	if err != nil {
+		t.Fatalf("unexpected error on update: %s", err)
+	}
+	if affected, _ := res.RowsAffected(); affected != 2 {
+		t.Errorf("expected the second update to be matched, but got %d rows affected", affected)
+	}
+	if _, err := db.Exec("UPDATE users SET name = ? WHERE id = ?", "jane", 2); err == nil {
+		t.Error("expected an error, the update was already matched")
+	}
+	if _, err := db.Exec("UPDATE users SET name = ? WHERE id = ?", "john", 1); err != nil {
+		t.Errorf("unexpected error on update: %s", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestMatchSkipsFulfilledExpectations(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New(AllowRejectedCallsOption(true))
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	for i := 0; i < 100; i++ {
+		mock.ExpectExec("INSERT INTO logs").WillReturnResult(NewResult(int64(i), 1))
+	}
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	for i := 0; i < 100; i++ {
+		if _, err := db.Exec("INSERT INTO logs VALUES (1)"); err != nil {
+			t.Fatalf("unexpected error on insert %d: %s", i, err)
+		}
+	}
+	if _, err := db.Exec("INSERT INTO logs VALUES (1)"); err == nil {
+		t.Error("expected an error, begin is the next expectation")
+	}
+
+	smock := mock.(*sqlmock)
+	if smock.index.cursor != 100 {
+		t.Errorf("expected the cursor to skip fulfilled expectations, but it is at %d", smock.index.cursor)
+	}
+
+	mock.MatchExpectationsInOrder(false)
+	if _, err := db.Exec("INSERT INTO logs VALUES (1)"); err == nil {
+		t.Error("expected an error, all inserts were already matched")
+	}
+	if n := len(smock.index.kinds[CallExec].items); n != 0 {
+		t.Errorf("expected fulfilled exec expectations to be dropped, but %d are left", n)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("unexpected error on begin: %s", err)
+	}
+	if err := tx.Rollback(); err != nil {
+		t.Errorf("unexpected error on rollback: %s", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func benchmarkMatch(b *testing.B, matcher QueryMatcher, ordered bool, fulfilled, pending int) {
+	_, mock, err := New(QueryMatcherOption(matcher))
+	if err != nil {
+		b.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	mock.MatchExpectationsInOrder(ordered)
+	smock := mock.(*sqlmock)
+
+	for i := 0; i < fulfilled; i++ {
+		ex := mock.ExpectExec(fmt.Sprintf("UPDATE done_%d SET", i)).WillReturnResult(NewResult(0, 1))
+		ex.triggered = true
+	}
+	var target *ExpectedExec
+	for i := 0; i < pending; i++ {
+		target = mock.ExpectExec(fmt.Sprintf("UPDATE pending_%d SET", i)).WillReturnResult(NewResult(0, 1))
+	}
+	query := fmt.Sprintf("UPDATE pending_%d SET", pending-1)
+	if ordered {
+		// the next pending expectation is matched in order
+		query = "UPDATE pending_0 SET"
+		target = smock.expected[fulfilled].(*ExpectedExec)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := smock.exec(query, nil); err != nil {
+			b.Fatal(err)
+		}
+		target.Lock()
+		target.triggered = false
+		target.Unlock()
+	}
+}
+
+func BenchmarkMatch(b *testing.B) {
+	matchers := map[string]QueryMatcher{
+		"regexp": QueryMatcherRegexp,
+		"equal":  QueryMatcherEqual,
+	}
+	for _, size := range []int{10, 100, 1000, 10000} {
+		for name, matcher := range matchers {
+			matcher := matcher
+			b.Run(fmt.Sprintf("%s/pending-%d", name, size), func(b *testing.B) {
+				benchmarkMatch(b, matcher, false, 0, size)
+			})
+			b.Run(fmt.Sprintf("%s/fulfilled-%d", name, size), func(b *testing.B) {
+				benchmarkMatch(b, matcher, false, size, 1)
+			})
+			b.Run(fmt.Sprintf("%s/ordered-%d", name, size), func(b *testing.B) {
+				benchmarkMatch(b, matcher, true, size, size)
+			})
+		}
+	}
+}
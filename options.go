@@ -1,6 +1,9 @@
 package sqlmock
 
-import "database/sql/driver"
+import (
+	"database/sql/driver"
+	"fmt"
+)
 
 // ValueConverterOption allows to create a sqlmock connection
 // with a custom ValueConverter to support drivers with special data types.
@@ -36,3 +39,99 @@ func MonitorPingsOption(monitorPings bool) func(*sqlmock) error {
 		return nil
 	}
 }
+
+// MatchPolicyOption sets how an expectation is selected, when
+// several pending expectations match a call in any order.
+// The default MatchPolicy is MatchFirst.
+func MatchPolicyOption(policy MatchPolicy) func(*sqlmock) error {
+	return func(s *sqlmock) error {
+		switch policy {
+		case MatchFirst, MatchMostSpecific, MatchStrict:
+			s.policy = policy
+			return nil
+		}
+		return fmt.Errorf("unknown match policy: %d", policy)
+	}
+}
+
+// LintOption makes ExpectationsWereMet fail with the warnings
+// of Sqlmock.Lint, if there are any for the pending expectations.
+func LintOption(enabled bool) func(*sqlmock) error {
+	return func(s *sqlmock) error {
+		s.lintOnMet = enabled
+		return nil
+	}
+}
+
+// CancelErrorFunc builds the error returned by the mock, when the
+// context of a call is done before its delay has passed. It receives
+// the context error, either context.Canceled or context.DeadlineExceeded.
+type CancelErrorFunc func(ctxErr error) error
+
+// CancelErrorOption allows to customize the error returned when the
+// context of a call is done, for example to return an error of a
+// specific driver. By default the returned error matches ErrCancelled
+// and wraps the context error. An error set on the expectation with
+// WillReturnCancelError takes precedence.
+func CancelErrorOption(fn CancelErrorFunc) func(*sqlmock) error {
+	return func(s *sqlmock) error {
+		s.cancelError = fn
+		return nil
+	}
+}
+
+// ClockOption allows to set the Clock used to delay results of
+// expectations with WillDelayFor and rows with RowDelay. Pass
+// a FakeClock in order to advance the time in tests manually,
+// instead of sleeping on the wall clock.
+func ClockOption(clock Clock) func(*sqlmock) error {
+	return func(s *sqlmock) error {
+		s.clock = clock
+		return nil
+	}
+}
+
+// LatencyOption allows to set a mock level Latency model, which
+// delays every Begin, Prepare, Query, Exec, Commit, Rollback and
+// Ping call by a base duration per call kind and a seeded random
+// jitter. An expectation delay set with WillDelayFor overrides it.
+func LatencyOption(latency *Latency) func(*sqlmock) error {
+	return func(s *sqlmock) error {
+		s.latency = latency
+		return nil
+	}
+}
+
+// FaultInjectionOption allows to randomly fail driver calls, rows
+// and results according to the seeded fault injection model, in
+// order to verify that the code under test survives driver failures
+// which were not scripted with expectations. See NewFaults.
+func FaultInjectionOption(faults *Faults) func(*sqlmock) error {
+	return func(s *sqlmock) error {
+		s.faults = faults
+		return nil
+	}
+}
+
+// DialectOption sets the SQL dialect of the mocked database. When it is
+// known, prepared statements report the number of placeholders they take,
+// so database/sql checks the number of arguments like with a real driver.
+func DialectOption(dialect Dialect) func(*sqlmock) error {
+	return func(s *sqlmock) error {
+		if _, err := parseDialect(string(dialect)); err != nil {
+			return err
+		}
+		s.dialect = dialect
+		return nil
+	}
+}
+
+// AllowRejectedCallsOption allows calls, which were not expected or did not
+// match the expectation, so ExpectationsWereMet does not fail because of
+// them. It is meant for tests, which deliberately probe such failures.
+func AllowRejectedCallsOption(allowed bool) func(*sqlmock) error {
+	return func(s *sqlmock) error {
+		s.allowRejected = allowed
+		return nil
+	}
+}
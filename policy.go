@@ -0,0 +1,171 @@
+package sqlmock
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// MatchPolicy selects the expectation of a call, when several
+// pending expectations match it. It applies only when expectations
+// are not matched in order.
+type MatchPolicy int
+
+const (
+	// MatchFirst selects the first matching expectation in the order
+	// of registration, it is the default policy
+	MatchFirst MatchPolicy = iota
+
+	// MatchMostSpecific selects the most specific matching expectation.
+	// Expected arguments beat none, exact argument values beat custom
+	// Argument matchers, which beat AnyArg. With equally specific
+	// arguments, SQL equal to the query beats a literal part of it,
+	// which beats a regular expression, and a longer literal part beats
+	// a shorter one. The first one registered wins
+	// among equally specific expectations.
+	MatchMostSpecific
+
+	// MatchStrict fails a call, which matches more than one expectation.
+	// Expectations of the same SQL and arguments are not ambiguous, they
+	// are matched in the order of registration.
+	MatchStrict
+)
+
+// candidate is a pending expectation, which matches a call
+type candidate struct {
+	e    expectation
+	sql  string
+	args []driver.Value
+	// specificity of the arguments and of the SQL
+	argScore, sqlScore int
+}
+
+// matchSQL is match for the calls with SQL, in any order the
+// expectation is selected by the match policy of the mock.
+// c.mu must be held.
+func (c *sqlmock) matchSQL(kind CallKind, query string, accept func(expectation) bool) (matched, next expectation, err error) {
+	if c.ordered || c.policy == MatchFirst {
+		return c.match(kind, query, accept)
+	}
+
+	var candidates []candidate
+	var blocked error
+	n, _ := c.queryMatcher.(normalizer)
+	c.index.lookup(kind, query, n).pending(func(e expectation) {
+		if !accept(e) {
+			return
+		}
+		if pred := c.predecessor(e); pred != nil {
+			if blocked == nil {
+				blocked = c.violation(e, pred)
+			}
+			return
+		}
+		cand := candidate{e: e, args: expectedArgs(e)}
+		cand.sql, _ = expectedSQL(e)
+		cand.argScore = argSpecificity(cand.args)
+		cand.sqlScore = c.sqlSpecificity(cand.sql, query)
+		candidates = append(candidates, cand)
+	})
+	if len(candidates) == 0 {
+		return nil, nil, blocked
+	}
+
+	best := candidates[0]
+	switch c.policy {
+	case MatchStrict:
+		var ambiguous bool
+		for _, cand := range candidates[1:] {
+			if cand.sql != best.sql || !reflect.DeepEqual(cand.args, best.args) {
+				ambiguous = true
+				break
+			}
+		}
+		if ambiguous {
+			msg := fmt.Sprintf("it is ambiguous, %d expectations match it:", len(candidates))
+			for _, cand := range candidates {
+				cand.e.Lock()
+				msg += fmt.Sprintf("\n  - declared at %s: %s", cand.e.common().declared, cand.e)
+				cand.e.Unlock()
+			}
+			return nil, nil, errors.New(msg)
+		}
+	case MatchMostSpecific:
+		for _, cand := range candidates[1:] {
+			if cand.argScore > best.argScore || (cand.argScore == best.argScore && cand.sqlScore > best.sqlScore) {
+				best = cand
+			}
+		}
+	}
+
+	best.e.Lock()
+	return best.e, nil, nil
+}
+
+// expectedArgs returns the arguments of expectations matched by query
+func expectedArgs(e expectation) []driver.Value {
+	switch e := e.(type) {
+	case *ExpectedQuery:
+		return e.args
+	case *ExpectedExec:
+		return e.args
+	}
+	return nil
+}
+
+// argSpecificity scores the expected arguments, any arguments score
+// the lowest and exact values the highest
+func argSpecificity(args []driver.Value) int {
+	if args == nil {
+		return 0
+	}
+	score := 1
+	for _, arg := range args {
+		switch arg.(type) {
+		case anyArgument:
+		case Argument:
+			score++
+		default:
+			score += 2
+		}
+	}
+	return score
+}
+
+// sqlSpecificity scores the expected SQL for the query, equal SQL
+// scores the highest and a regular expression the lowest. A literal
+// part of the query scores by its length, so that the longer one,
+// which covers more of the query, beats a shorter one.
+func (c *sqlmock) sqlSpecificity(expectSQL, query string) int {
+	exact := len(stripQuery(query)) + 2
+	switch c.queryMatcher.(type) {
+	case equalMatcher:
+		return exact
+	case regexpMatcher:
+		if !literal(expectSQL) {
+			return 0
+		}
+		text := stripQuery(unescape(expectSQL))
+		if text == stripQuery(query) {
+			return exact
+		}
+		return 1 + len(text)
+	}
+	return 0
+}
+
+// literal returns whether a regular expression matches only its text
+func literal(expr string) bool {
+	for i := 0; i < len(expr); i++ {
+		if expr[i] == '\\' {
+			i++
+			continue
+		}
+		if strings.IndexByte(".+*?()|[]{}^$", expr[i]) >= 0 {
+			return false
+		}
+	}
+	return true
+}
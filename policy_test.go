@@ -0,0 +1,141 @@
+package sqlmock
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMatchFirstPolicy(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+	mock.MatchExpectationsInOrder(false)
+
+	mock.ExpectExec("UPDATE users").WithArgs(AnyArg()).WillReturnResult(NewResult(0, 1))
+	mock.ExpectExec(`UPDATE users SET active = \?`).WithArgs(true).WillReturnResult(NewResult(0, 2))
+
+	res, err := db.Exec("UPDATE users SET active = ?", true)
+	if err != nil {
+		t.Fatalf("unexpected error on update: %s", err)
+	}
+	if affected, _ := res.RowsAffected(); affected != 1 {
+		t.Errorf("expected the first expectation to be matched, but got %d rows affected", affected)
+	}
+}
+
+func TestMatchMostSpecificPolicy(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New(MatchPolicyOption(MatchMostSpecific))
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+	mock.MatchExpectationsInOrder(false)
+
+	mock.ExpectExec("UPDATE users").WillReturnResult(NewResult(0, 1))
+	mock.ExpectExec("UPDATE (.+) SET").WithArgs(AnyArg()).WillReturnResult(NewResult(0, 2))
+	mock.ExpectExec("UPDATE (.+) SET").WithArgs(true).WillReturnResult(NewResult(0, 3))
+	mock.ExpectExec(`UPDATE users SET active = \?`).WithArgs(true).WillReturnResult(NewResult(0, 4))
+
+	for _, expected := range []int64{4, 3, 2, 1} {
+		res, err := db.Exec("UPDATE users SET active = ?", true)
+		if err != nil {
+			t.Fatalf("unexpected error on update: %s", err)
+		}
+		if affected, _ := res.RowsAffected(); affected != expected {
+			t.Errorf("expected the expectation affecting %d rows to be matched, but got %d", expected, affected)
+		}
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestMatchMostSpecificPolicyPrefersLongerLiteral(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New(MatchPolicyOption(MatchMostSpecific))
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+	mock.MatchExpectationsInOrder(false)
+
+	mock.ExpectQuery("SELECT").WillReturnRows(NewRows([]string{"name"}).AddRow("broad"))
+	mock.ExpectQuery("SELECT name FROM users").WillReturnRows(NewRows([]string{"name"}).AddRow("john"))
+
+	for _, expected := range []string{"john", "broad"} {
+		var name string
+		if err := db.QueryRow("SELECT name FROM users WHERE id = 1").Scan(&name); err != nil {
+			t.Fatalf("unexpected error on select: %s", err)
+		}
+		if name != expected {
+			t.Errorf("expected the rows of %q to be returned, but got %q", expected, name)
+		}
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestMatchStrictPolicy(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New(MatchPolicyOption(MatchStrict))
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+	mock.MatchExpectationsInOrder(false)
+
+	rows := NewRows([]string{"id"}).AddRow(1)
+	mock.ExpectQuery("SELECT id FROM users").WithArgs(1).WillReturnRows(rows)
+	mock.ExpectQuery("SELECT id FROM users").WithArgs(1).WillReturnRows(rows)
+	mock.ExpectQuery("SELECT (.+) FROM users").WithArgs(2).WillReturnRows(rows)
+	mock.ExpectQuery("SELECT id FROM").WithArgs(2).WillReturnRows(rows)
+
+	// the same expectation twice is not ambiguous
+	for i := 0; i < 2; i++ {
+		if _, err := db.Query("SELECT id FROM users WHERE id = ?", 1); err != nil {
+			t.Errorf("unexpected error on query: %s", err)
+		}
+	}
+
+	_, err = db.Query("SELECT id FROM users WHERE id = ?", 2)
+	if err == nil {
+		t.Fatal("expected an error for an ambiguous query")
+	}
+	if !strings.Contains(err.Error(), "it is ambiguous, 2 expectations match it") || strings.Count(err.Error(), "declared at policy_test.go:") != 2 {
+		t.Errorf("expected the error to list the competing expectations, but got: %s", err)
+	}
+}
+
+func TestMatchStrictPolicyKeepsSQLInError(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New(MatchPolicyOption(MatchStrict))
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+	mock.MatchExpectationsInOrder(false)
+
+	rows := NewRows([]string{"id"}).AddRow(1)
+	mock.ExpectQuery("SELECT id FROM users WHERE name LIKE '%a%'").WillReturnRows(rows)
+	mock.ExpectQuery("SELECT (.+) FROM users WHERE name LIKE '%a%'").WillReturnRows(rows)
+
+	_, err = db.Query("SELECT id FROM users WHERE name LIKE '%a%'")
+	if err == nil {
+		t.Fatal("expected an error for an ambiguous query")
+	}
+	if strings.Contains(err.Error(), "MISSING") || !strings.Contains(err.Error(), "matches sql: 'SELECT (.+) FROM users WHERE name LIKE '%a%''") {
+		t.Errorf("expected the error to show the expected SQL as is, but got: %s", err)
+	}
+}
+
+func TestUnknownMatchPolicy(t *testing.T) {
+	t.Parallel()
+	if _, _, err := New(MatchPolicyOption(MatchPolicy(42))); err == nil {
+		t.Error("expected an error for unknown match policy")
+	}
+}
@@ -1,16 +1,30 @@
 package sqlmock
 
 import (
+	"bytes"
 	"fmt"
 	"regexp"
 	"strings"
 )
 
-var re = regexp.MustCompile("\\s+")
-
 // strip out new lines and trim spaces
 func stripQuery(q string) (s string) {
-	return strings.TrimSpace(re.ReplaceAllString(q, " "))
+	var b bytes.Buffer
+	b.Grow(len(q))
+	space := false
+	for i := 0; i < len(q); i++ {
+		switch ch := q[i]; ch {
+		case ' ', '\t', '\n', '\f', '\r':
+			space = true
+		default:
+			if space {
+				b.WriteByte(' ')
+				space = false
+			}
+			b.WriteByte(ch)
+		}
+	}
+	return strings.TrimSpace(b.String())
 }
 
 // QueryMatcher is an SQL query string matcher interface,
@@ -42,27 +56,50 @@ func (f QueryMatcherFunc) Match(expectedSQL, actualSQL string) error {
 // QueryMatcherRegexp is the default SQL query matcher
 // used by sqlmock. It parses expectedSQL to a regular
 // expression and attempts to match actualSQL.
-var QueryMatcherRegexp QueryMatcher = QueryMatcherFunc(func(expectedSQL, actualSQL string) error {
-	expect := stripQuery(expectedSQL)
-	actual := stripQuery(actualSQL)
-	re, err := regexp.Compile(expect)
+// The expected SQL of an expectation is compiled and
+// validated once, when the expectation is registered.
+var QueryMatcherRegexp QueryMatcher = regexpMatcher{}
+
+type regexpMatcher struct{}
+
+func (m regexpMatcher) Match(expectedSQL, actualSQL string) error {
+	re, err := m.compile(expectedSQL)
 	if err != nil {
 		return err
 	}
+	return matchRegexp(re, actualSQL)
+}
+
+func (regexpMatcher) compile(expectedSQL string) (*regexp.Regexp, error) {
+	return regexp.Compile(stripQuery(expectedSQL))
+}
+
+func matchRegexp(re *regexp.Regexp, actualSQL string) error {
+	actual := stripQuery(actualSQL)
 	if !re.MatchString(actual) {
 		return fmt.Errorf(`could not match actual sql: "%s" with expected regexp "%s"`, actual, re.String())
 	}
 	return nil
-})
+}
 
 // QueryMatcherEqual is the SQL query matcher
 // which simply tries a case sensitive match of
 // expected and actual SQL strings without whitespace.
-var QueryMatcherEqual QueryMatcher = QueryMatcherFunc(func(expectedSQL, actualSQL string) error {
+// Expectations are looked up by their SQL, so matching
+// does not slow down with the number of expectations.
+var QueryMatcherEqual QueryMatcher = equalMatcher{}
+
+type equalMatcher struct{}
+
+func (equalMatcher) Match(expectedSQL, actualSQL string) error {
 	expect := stripQuery(expectedSQL)
 	actual := stripQuery(actualSQL)
 	if actual != expect {
 		return fmt.Errorf(`actual sql: "%s" does not equal to expected "%s"`, actual, expect)
 	}
 	return nil
-})
+}
+
+func (equalMatcher) normalize(sql string) string {
+	return stripQuery(sql)
+}
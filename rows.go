@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"strings"
+	"time"
 )
 
 const invalidate = "☠☠☠ MEMORY OVERWRITTEN ☠☠☠ "
@@ -23,10 +24,12 @@ var CSVColumnParser = func(s string) []byte {
 }
 
 type rowSets struct {
-	sets []*Rows
-	pos  int
-	ex   *ExpectedQuery
-	raw  [][]byte
+	sets       []*Rows
+	pos        int
+	ex         *ExpectedQuery
+	epoch      int // epoch of the expectation the rows were returned for
+	raw        [][]byte
+	beforeNext func(delay time.Duration) error
 }
 
 func (rs *rowSets) Columns() []string {
@@ -35,13 +38,68 @@ func (rs *rowSets) Columns() []string {
 
 func (rs *rowSets) Close() error {
 	rs.invalidateRaw()
-	rs.ex.rowsWereClosed = true
+	rs.ex.Lock()
+	if rs.epoch == rs.ex.epoch {
+		rs.ex.rowsWereClosed = true
+	}
+	mock := rs.ex.mock
+	rs.ex.Unlock()
+
+	if mock != nil {
+		mock.changed()
+	}
 	return rs.sets[rs.pos].closeErr
 }
 
+// rowsCloner is implemented by rows, which may be returned
+// by more than one call, each call gets rows of its own
+type rowsCloner interface {
+	cloneFor(ex *ExpectedQuery) driver.Rows
+}
+
+func (rs *rowSets) cloneFor(ex *ExpectedQuery) driver.Rows {
+	return rs.clone(ex)
+}
+
+func (rs *rowSets) clone(ex *ExpectedQuery) *rowSets {
+	sets := make([]*Rows, len(rs.sets))
+	for i, set := range rs.sets {
+		cp := *set
+		cp.pos = 0
+		sets[i] = &cp
+	}
+	return &rowSets{sets: sets, ex: ex, epoch: ex.epoch}
+}
+
+// nextBinder is implemented by rows which run a hook
+// before every row, it delays the row with the context
+// of the query the rows were returned for
+type nextBinder interface {
+	bindBeforeNext(fn func(delay time.Duration) error)
+}
+
+func (rs *rowSets) bindBeforeNext(fn func(delay time.Duration) error) {
+	rs.beforeNext = fn
+}
+
+// beforeRow delays the next row, when bound to a query
+// it returns the cancellation error as soon as the query
+// context is done
+func (rs *rowSets) beforeRow(delay time.Duration) error {
+	if rs.beforeNext == nil {
+		time.Sleep(delay)
+		return nil
+	}
+	return rs.beforeNext(delay)
+}
+
 // advances to next row
 func (rs *rowSets) Next(dest []driver.Value) error {
 	r := rs.sets[rs.pos]
+	if err := rs.beforeRow(r.delay); err != nil {
+		return err
+	}
+
 	r.pos++
 	rs.invalidateRaw()
 	if r.pos > len(r.rows) {
@@ -125,6 +183,7 @@ type Rows struct {
 	pos       int
 	nextErr   map[int]error
 	closeErr  error
+	delay     time.Duration
 }
 
 // NewRows allows Rows to be created from a
@@ -159,6 +218,15 @@ func (r *Rows) RowError(row int, err error) *Rows {
 	return r
 }
 
+// RowDelay allows to set a duration for which
+// every call to rows.Next will be delayed. If the
+// query context is done while waiting, rows.Next
+// returns the cancellation error
+func (r *Rows) RowDelay(duration time.Duration) *Rows {
+	r.delay = duration
+	return r
+}
+
 // AddRow composed from database driver.Value slice
 // return the same instance to perform subsequent actions.
 // Note that the number of values must match the number
@@ -28,6 +28,10 @@ type rowSetsWithDefinition struct {
 	*rowSets
 }
 
+func (rs *rowSetsWithDefinition) cloneFor(ex *ExpectedQuery) driver.Rows {
+	return &rowSetsWithDefinition{rs.rowSets.clone(ex)}
+}
+
 // Implement the "RowsColumnTypeDatabaseTypeName" interface
 func (rs *rowSetsWithDefinition) ColumnTypeDatabaseTypeName(index int) string {
 	return rs.getDefinition(index).DbType()
@@ -0,0 +1,120 @@
+package sqlmock
+
+import (
+	"database/sql/driver"
+	"time"
+)
+
+// Response is the outcome of a single call matching an expectation,
+// which responds with a sequence, see WillReturnSequence
+type Response struct {
+	// Rows are returned by a query
+	Rows *Rows
+	// Result is returned by an exec
+	Result driver.Result
+	// Err is returned instead of rows or a result
+	Err error
+	// Delay delays the response, it may be cancelled with the context.
+	// A response with a delay only stands for a call, which times out.
+	Delay time.Duration
+}
+
+// delay returns the delay of the response, a response without
+// a delay is subject to the latency of the mock
+func (r Response) delay() *time.Duration {
+	if r.Delay <= 0 {
+		return nil
+	}
+	return &r.Delay
+}
+
+// sequence holds the responses to the calls matching an expectation,
+// one response per call in the order of calls
+type sequence struct {
+	responses []Response
+	calls     int
+}
+
+func (s *sequence) sequenced() *sequence {
+	return s
+}
+
+// done returns whether all the responses were returned
+func (s *sequence) done() bool {
+	return s.calls >= len(s.responses)
+}
+
+// next returns the response to the next call, if the expectation
+// responds with a sequence, the expectation must be locked
+func (s *sequence) next() (Response, bool) {
+	if s.done() {
+		return Response{}, false
+	}
+	r := s.responses[s.calls]
+	s.calls++
+	return r, true
+}
+
+// WillReturnSequence makes the expectation match as many calls as there are
+// responses and answer each call with the next response, so that the first
+// call may fail and the next one succeed. A call after the last response is
+// not expected. In ordered mode, once the first call matched, the expectations
+// registered after it may match between the calls, like a retried Begin.
+func (e *ExpectedQuery) WillReturnSequence(responses ...Response) *ExpectedQuery {
+	e.Lock()
+	defer e.Unlock()
+	e.responses = responses
+	e.calls = 0
+	return e
+}
+
+func (e *ExpectedQuery) fulfilled() bool {
+	return e.triggered && e.done()
+}
+
+// WillReturnSequence makes the expectation match as many calls as there are
+// responses and answer each call with the next response, so that the first
+// call may fail and the next one succeed. A call after the last response is
+// not expected. In ordered mode, once the first call matched, the expectations
+// registered after it may match between the calls, like a retried Begin.
+func (e *ExpectedExec) WillReturnSequence(responses ...Response) *ExpectedExec {
+	e.Lock()
+	defer e.Unlock()
+	e.responses = responses
+	e.calls = 0
+	return e
+}
+
+func (e *ExpectedExec) fulfilled() bool {
+	return e.triggered && e.done()
+}
+
+// WillReturnSequence makes the expectation match as many commits as there
+// are responses and answer each one with the error and delay of the next
+// response. A commit after the last response is not expected. In ordered
+// mode, once the first commit matched, the expectations registered after
+// it may match between the commits, like the Begin of the retry.
+func (e *ExpectedCommit) WillReturnSequence(responses ...Response) *ExpectedCommit {
+	e.Lock()
+	defer e.Unlock()
+	e.responses = responses
+	e.calls = 0
+	return e
+}
+
+func (e *ExpectedCommit) fulfilled() bool {
+	return e.triggered && e.done()
+}
+
+// respond returns a copy of the expectation, which answers a commit
+// with the response, the expectation must be locked
+func (e *ExpectedCommit) respond(r Response) *ExpectedCommit {
+	hit := &ExpectedCommit{delay: r.delay()}
+	hit.err = r.Err
+	hit.cancelErr = e.cancelErr
+	hit.declared = e.declared
+	hit.release = e.release
+	hit.breaks = e.breaks
+	hit.triggered = true
+	return hit
+}
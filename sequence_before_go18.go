@@ -0,0 +1,46 @@
+// +build !go1.8
+
+package sqlmock
+
+import "fmt"
+
+// respond returns a copy of the expectation, which answers a query with
+// the response, closing its rows is recorded on the expectation. A response,
+// which only delays, fails once the delay passed, unless the context is done
+// before. The expectation must be locked.
+func (e *ExpectedQuery) respond(r Response) (*ExpectedQuery, error) {
+	hit := &ExpectedQuery{delay: r.delay()}
+	hit.expectSQL = e.expectSQL
+	hit.args = e.args
+	hit.err = r.Err
+	hit.cancelErr = e.cancelErr
+	hit.declared = e.declared
+	hit.release = e.release
+	hit.breaks = e.breaks
+	hit.triggered = true
+	if r.Rows != nil {
+		hit.rows = (&rowSets{sets: []*Rows{r.Rows}}).clone(e)
+	}
+	if hit.err == nil && hit.rows == nil {
+		hit.err = fmt.Errorf("%s: response %d of ExpectQuery '%s' returns neither rows nor an error", e.declared, e.calls, e.expectSQL)
+	}
+	return hit, hit.err
+}
+
+// respond returns a copy of the expectation, which answers an exec with
+// the response, the expectation must be locked
+func (e *ExpectedExec) respond(r Response) (*ExpectedExec, error) {
+	hit := &ExpectedExec{result: r.Result, delay: r.delay()}
+	hit.expectSQL = e.expectSQL
+	hit.args = e.args
+	hit.err = r.Err
+	hit.cancelErr = e.cancelErr
+	hit.declared = e.declared
+	hit.release = e.release
+	hit.breaks = e.breaks
+	hit.triggered = true
+	if hit.err == nil && hit.result == nil {
+		hit.err = fmt.Errorf("%s: response %d of ExpectExec '%s' returns neither a result nor an error", e.declared, e.calls, e.expectSQL)
+	}
+	return hit, hit.err
+}
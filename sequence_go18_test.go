@@ -0,0 +1,188 @@
+// +build go1.8
+
+package sqlmock
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWillReturnSequenceForRetries(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	deadlock := errors.New("deadlock detected")
+	mock.ExpectQuery("SELECT balance").WillReturnSequence(
+		Response{Err: deadlock},
+		Response{Delay: time.Second},
+		Response{Rows: NewRows([]string{"balance"}).AddRow(10)},
+	)
+
+	var balance int
+	if err := db.QueryRow("SELECT balance FROM accounts").Scan(&balance); err != deadlock {
+		t.Errorf("expected the deadlock error on the first call, but got: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := db.QueryRowContext(ctx, "SELECT balance FROM accounts").Scan(&balance); err == nil {
+		t.Error("expected the second call to time out")
+	}
+
+	if err := db.QueryRow("SELECT balance FROM accounts").Scan(&balance); err != nil {
+		t.Fatalf("unexpected error on the third call: %s", err)
+	}
+	if balance != 10 {
+		t.Errorf("expected the rows of the third response, but got: %d", balance)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestWillReturnSequenceRunsPastTheEnd(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New(AllowRejectedCallsOption(true))
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectExec("UPDATE jobs").WillReturnSequence(
+		Response{Err: errors.New("lock wait timeout")},
+		Response{Result: NewResult(0, 1)},
+	)
+	mock.ExpectBegin()
+	mock.ExpectCommit().WillReturnSequence(Response{Err: errors.New("serialization failure")})
+
+	if _, err := db.Exec("UPDATE jobs SET done = 1"); err == nil {
+		t.Error("expected an error on the first call")
+	}
+	if err := mock.ExpectationsWereMet(); err == nil {
+		t.Error("expected an error, the sequence is not complete")
+	}
+	res, err := db.Exec("UPDATE jobs SET done = 1")
+	if err != nil {
+		t.Fatalf("unexpected error on the second call: %s", err)
+	}
+	if n, _ := res.RowsAffected(); n != 1 {
+		t.Errorf("expected the result of the second response, but got %d rows affected", n)
+	}
+	if _, err := db.Exec("UPDATE jobs SET done = 1"); err == nil {
+		t.Error("expected an error, the sequence has no more responses")
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("unexpected error on begin: %s", err)
+	}
+	if err := tx.Commit(); err == nil || err.Error() != "serialization failure" {
+		t.Errorf("expected the error of the commit response, but got: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestWillReturnSequenceIsValidated(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT 1").WillReturnSequence(Response{})
+	if err := mock.ExpectationsWereMet(); err == nil {
+		t.Error("expected an error, the response returns neither rows nor an error")
+	}
+}
+
+func TestWillReturnSequenceInOrderedRetryLoop(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	deadlock := errors.New("deadlock detected")
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE accounts").WillReturnSequence(
+		Response{Err: deadlock},
+		Response{Result: NewResult(0, 1)},
+	)
+	mock.ExpectRollback()
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+
+	for attempt := 1; ; attempt++ {
+		tx, err := db.Begin()
+		if err != nil {
+			t.Fatalf("unexpected error on begin of attempt %d: %s", attempt, err)
+		}
+		if _, err := tx.Exec("UPDATE accounts SET balance = 10"); err != nil {
+			if err != deadlock {
+				t.Fatalf("expected the deadlock error on attempt %d, but got: %s", attempt, err)
+			}
+			if err := tx.Rollback(); err != nil {
+				t.Fatalf("unexpected error on rollback of attempt %d: %s", attempt, err)
+			}
+			continue
+		}
+		if err := tx.Commit(); err != nil {
+			t.Fatalf("unexpected error on commit of attempt %d: %s", attempt, err)
+		}
+		if attempt != 2 {
+			t.Errorf("expected to succeed on the second attempt, but did on attempt %d", attempt)
+		}
+		break
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestCommitWillReturnSequenceInOrderedRetryLoop(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	serialization := errors.New("could not serialize access due to concurrent update")
+	mock.ExpectBegin()
+	mock.ExpectCommit().WillReturnSequence(
+		Response{Err: serialization},
+		Response{},
+	)
+	mock.ExpectBegin()
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("unexpected error on the first begin: %s", err)
+	}
+	if err := tx.Commit(); err != serialization {
+		t.Fatalf("expected the serialization failure on the first commit, but got: %v", err)
+	}
+
+	tx, err = db.Begin()
+	if err != nil {
+		t.Fatalf("unexpected error on the second begin: %s", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("unexpected error on the second commit: %s", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
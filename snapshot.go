@@ -0,0 +1,151 @@
+package sqlmock
+
+import (
+	"database/sql/driver"
+	"fmt"
+)
+
+// Snapshot is the state of the expectations, stubs, call journal and
+// open transactions of a mock, as saved by Sqlmock.Snapshot, to be
+// restored between subtests sharing a common baseline of expectations
+type Snapshot struct {
+	mock     *sqlmock
+	expected []savedExpectation
+	stubs    []expectation
+	journal  []Call
+	txs      []*txState
+}
+
+// savedExpectation is the state of an expectation, which is
+// changed by the calls matching it
+type savedExpectation struct {
+	e         expectation
+	triggered bool
+	invalid   error
+	after     []expectation
+	rows      driver.Rows
+	closed    bool
+	calls     int
+}
+
+// Reset removes all expectations and stubs and clears the call journal.
+// The options of the mock are kept. Rows and statements, which are still
+// open, may be closed afterwards without any effect on the mock, and
+// transactions, which are still open, are no longer reported as leaked.
+func (c *sqlmock) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, e := range c.expected {
+		e.Lock()
+		e.common().epoch++
+		e.Unlock()
+	}
+	c.expected = nil
+	c.index = index{}
+	c.stubs = nil
+	c.journal = nil
+	c.txs = nil
+	c.notify()
+}
+
+// Snapshot saves the state of the expectations, stubs, call journal
+// and open transactions
+func (c *sqlmock) Snapshot() *Snapshot {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	s := &Snapshot{
+		mock:    c,
+		stubs:   append([]expectation(nil), c.stubs...),
+		journal: append([]Call(nil), c.journal...),
+		txs:     append([]*txState(nil), c.txs...),
+	}
+	for _, e := range c.expected {
+		e.Lock()
+		ce := e.common()
+		saved := savedExpectation{
+			e:         e,
+			triggered: ce.triggered,
+			invalid:   ce.invalid,
+			after:     append([]expectation(nil), ce.after...),
+		}
+		switch e := e.(type) {
+		case *ExpectedQuery:
+			saved.closed = e.rowsWereClosed
+			if rows, ok := e.rows.(rowsCloner); ok {
+				saved.rows = rows.cloneFor(e)
+			}
+		case *ExpectedPrepare:
+			saved.closed = e.wasClosed
+		}
+		if seq, ok := e.(interface{ sequenced() *sequence }); ok {
+			saved.calls = seq.sequenced().calls
+		}
+		e.Unlock()
+		s.expected = append(s.expected, saved)
+	}
+	return s
+}
+
+// Restore rolls the expectations, stubs and call journal back to the
+// snapshot, expectations registered after it are removed. Rows and
+// statements, which are still open, may be closed afterwards without
+// any effect on the mock. Transactions begun after the snapshot are no
+// longer reported as leaked, the ones open at the snapshot still are,
+// unless they were committed or rolled back since.
+func (c *sqlmock) Restore(s *Snapshot) error {
+	if s == nil || s.mock != c {
+		return fmt.Errorf("the snapshot was not taken from this mock")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, e := range c.expected {
+		e.Lock()
+		e.common().epoch++
+		e.Unlock()
+	}
+
+	n, _ := c.queryMatcher.(normalizer)
+	c.expected = nil
+	c.index = index{}
+	for _, saved := range s.expected {
+		e := saved.e
+		e.Lock()
+		ce := e.common()
+		ce.triggered = saved.triggered
+		ce.invalid = saved.invalid
+		ce.after = append([]expectation(nil), saved.after...)
+		switch e := e.(type) {
+		case *ExpectedQuery:
+			e.rowsWereClosed = saved.closed
+			if rows, ok := saved.rows.(rowsCloner); ok {
+				e.rows = rows.cloneFor(e)
+			}
+		case *ExpectedPrepare:
+			e.wasClosed = saved.closed
+		}
+		if seq, ok := e.(interface{ sequenced() *sequence }); ok {
+			seq.sequenced().calls = saved.calls
+		}
+		if b, ok := e.(interface{ rearm() }); ok && !saved.triggered {
+			b.rearm()
+		}
+		e.Unlock()
+
+		c.expected = append(c.expected, e)
+		c.index.add(e, n)
+	}
+	c.stubs = append([]expectation(nil), s.stubs...)
+	c.journal = append([]Call(nil), s.journal...)
+	c.txs = nil
+	for _, tx := range s.txs {
+		if !tx.done {
+			c.txs = append(c.txs, tx)
+		}
+	}
+	c.notify()
+	return nil
+}
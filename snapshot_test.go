@@ -0,0 +1,198 @@
+package sqlmock
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestResetClearsExpectationsAndJournal(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE users").WillReturnResult(NewResult(0, 1))
+	mock.StubQuery("SELECT 1").WillReturnRows(NewRows([]string{"ok"}).AddRow(1))
+	if _, err := db.Begin(); err != nil {
+		t.Fatalf("unexpected error on begin: %s", err)
+	}
+
+	mock.Reset()
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expected no expectations after reset, but got: %s", err)
+	}
+	if calls := mock.Calls(); len(calls) != 0 {
+		t.Errorf("expected an empty journal after reset, but got: %+v", calls)
+	}
+
+	mock.ExpectExec("DELETE FROM users").WillReturnResult(NewResult(0, 1))
+	if _, err := db.Exec("DELETE FROM users"); err != nil {
+		t.Errorf("unexpected error on delete after reset: %s", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestSnapshotRestoreBetweenSubtests(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT id FROM users").
+		WillReturnRows(NewRows([]string{"id"}).AddRow(1)).
+		RowsWillBeClosed()
+	baseline := mock.Snapshot()
+
+	for _, table := range []string{"orders", "invoices"} {
+		t.Run(table, func(t *testing.T) {
+			if err := mock.Restore(baseline); err != nil {
+				t.Fatalf("unexpected error on restore: %s", err)
+			}
+			mock.ExpectExec("DELETE FROM " + table).WillReturnResult(NewResult(0, 1))
+
+			var id int
+			if err := db.QueryRow("SELECT id FROM users").Scan(&id); err != nil {
+				t.Fatalf("unexpected error on select: %s", err)
+			}
+			if id != 1 {
+				t.Errorf("expected the baseline row, but got: %d", id)
+			}
+			if _, err := db.Exec("DELETE FROM " + table); err != nil {
+				t.Errorf("unexpected error on delete: %s", err)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+			if calls := mock.Calls(); len(calls) != 2 {
+				t.Errorf("expected the journal of the subtest only, but got: %+v", calls)
+			}
+		})
+	}
+}
+
+func TestRestoreTransactionsOpenAtSnapshot(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	baselineTx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("unexpected error on begin: %s", err)
+	}
+	baseline := mock.Snapshot()
+
+	mock.ExpectBegin()
+	if _, err := db.Begin(); err != nil {
+		t.Fatalf("unexpected error on begin: %s", err)
+	}
+	if err := mock.Restore(baseline); err != nil {
+		t.Fatalf("unexpected error on restore: %s", err)
+	}
+
+	err = mock.ExpectationsWereMet()
+	if err == nil || !strings.Contains(err.Error(), "there are 1 transactions") {
+		t.Fatalf("expected only the transaction open at the snapshot to be leaked, but got: %v", err)
+	}
+
+	mock.ExpectRollback()
+	if err := baselineTx.Rollback(); err != nil {
+		t.Fatalf("unexpected error on rollback: %s", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestRowsSetAfterRestoreAreTracked(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	q := mock.ExpectQuery("SELECT id FROM users")
+	baseline := mock.Snapshot()
+
+	for _, id := range []int{1, 2} {
+		t.Run(fmt.Sprint(id), func(t *testing.T) {
+			if err := mock.Restore(baseline); err != nil {
+				t.Fatalf("unexpected error on restore: %s", err)
+			}
+			q.WillReturnRows(NewRows([]string{"id"}).AddRow(id)).RowsWillBeClosed()
+
+			var got int
+			if err := db.QueryRow("SELECT id FROM users").Scan(&got); err != nil {
+				t.Fatalf("unexpected error on select: %s", err)
+			}
+			if got != id {
+				t.Errorf("expected the row of the subtest, but got: %d", got)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func TestRestoreIgnoresRowsInFlight(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT id FROM users").
+		WillReturnRows(NewRows([]string{"id"}).AddRow(1)).
+		RowsWillBeClosed()
+	baseline := mock.Snapshot()
+
+	rows, err := db.Query("SELECT id FROM users")
+	if err != nil {
+		t.Fatalf("unexpected error on select: %s", err)
+	}
+	if err := mock.Restore(baseline); err != nil {
+		t.Fatalf("unexpected error on restore: %s", err)
+	}
+	rows.Close()
+
+	rows, err = db.Query("SELECT id FROM users")
+	if err != nil {
+		t.Fatalf("unexpected error on select after restore: %s", err)
+	}
+	if err := mock.ExpectationsWereMet(); err == nil {
+		t.Error("expected an error, the rows of the restored expectation were not closed")
+	}
+	rows.Close()
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestRestoreSnapshotOfAnotherMock(t *testing.T) {
+	t.Parallel()
+	_, mock, err := New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	_, other, err := New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	if err := mock.Restore(other.Snapshot()); err == nil {
+		t.Error("expected an error, the snapshot was taken from another mock")
+	}
+}
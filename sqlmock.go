@@ -13,7 +13,9 @@ package sqlmock
 import (
 	"database/sql"
 	"database/sql/driver"
+	"errors"
 	"fmt"
+	"sync"
 	"testing"
 	"time"
 )
@@ -29,8 +31,45 @@ type SqlmockCommon interface {
 
 	// ExpectationsWereMet checks whether all queued expectations
 	// were met in order. If any of them was not met - an error is returned.
+	// Transactions, which were neither committed nor rolled back, are
+	// reported as well.
 	ExpectationsWereMet() error
 
+	// StubQuery registers a stub, which answers queries matching the
+	// SQL when no expectation matches them. Stubs are not required to
+	// be called and may be called any number of times. Statements are
+	// prepared for the SQL of a stub, unless an expectation matches.
+	StubQuery(expectedSQL string) *ExpectedQuery
+
+	// StubExec registers a stub, which answers execs matching the
+	// SQL when no expectation matches them. Stubs are not required to
+	// be called and may be called any number of times. Statements are
+	// prepared for the SQL of a stub, unless an expectation matches.
+	StubExec(expectedSQL string) *ExpectedExec
+
+	// Calls returns the journal of the calls received by the mock,
+	// telling whether an expectation or a stub answered each one.
+	Calls() []Call
+
+	// Reset removes all expectations and stubs and clears the call
+	// journal, so the mock may be reused by another test case.
+	Reset()
+
+	// Snapshot saves the state of the expectations, stubs, call journal
+	// and open transactions, to be restored by Restore between subtests.
+	Snapshot() *Snapshot
+
+	// Restore rolls the mock back to the state saved by Snapshot, the
+	// transactions begun since are no longer reported as leaked.
+	Restore(s *Snapshot) error
+
+	// Lint analyzes the pending expectations for likely mistakes, such as
+	// expectations shadowed by a broader one registered before them,
+	// duplicates, prepared statements never used, conflicting arguments
+	// and expectations never reached in ordered mode. The warnings point
+	// at the lines which declared the expectations.
+	Lint() []LintWarning
+
 	// ExpectPrepare expects Prepare() to be called with expectedSQL query.
 	// the *ExpectedPrepare allows to mock database response.
 	// Note that you may expect Query() or Exec() on the *ExpectedPrepare
@@ -45,6 +84,14 @@ type SqlmockCommon interface {
 	// the *ExpectedExec allows to mock database response
 	ExpectExec(expectedSQL string) *ExpectedExec
 
+	// ExpectConnect expects a new connection to be opened by the
+	// driver. the *ExpectedConnect allows to mock connection failures.
+	//
+	// Connections are opened by database/sql whenever its pool needs
+	// one, so they are not required to be expected. A connection which
+	// does not match the next pending ExpectedConnect is opened.
+	ExpectConnect() *ExpectedConnect
+
 	// ExpectBegin expects *sql.DB.Begin to be called.
 	// the *ExpectedBegin allows to mock database response
 	ExpectBegin() *ExpectedBegin
@@ -94,16 +141,32 @@ type SqlmockCommon interface {
 }
 
 type sqlmock struct {
-	ordered      bool
-	dsn          string
-	opened       int
-	drv          *mockDriver
-	converter    driver.ValueConverter
-	queryMatcher QueryMatcher
-	monitorPings bool
+	mu            sync.Mutex
+	ordered       bool
+	dsn           string
+	opened        int
+	drv           *mockDriver
+	converter     driver.ValueConverter
+	queryMatcher  QueryMatcher
+	monitorPings  bool
+	cancelError   CancelErrorFunc
+	clock         Clock
+	latency       *Latency
+	faults        *Faults
+	dialect       Dialect
+	lintOnMet     bool
+	policy        MatchPolicy
+	ignored       []ignoreRule
+	ignoredKinds  map[CallKind]bool
+	allowRejected bool
+	waiting       chan struct{}
+	txs           []*txState
 	t			 *testing.T
 
 	expected []expectation
+	index    index
+	stubs    []expectation
+	journal  []Call
 }
 
 func (c *sqlmock) open(options []func(*sqlmock) error) (*sql.DB, Sqlmock, error) {
@@ -111,10 +174,36 @@ func (c *sqlmock) open(options []func(*sqlmock) error) (*sql.DB, Sqlmock, error)
 	if err != nil {
 		return db, c, err
 	}
+	if err := c.configure(options); err != nil {
+		// the dsn is released, so that it may be used again
+		db.Close()
+		c.drv.release(c)
+		return db, c, err
+	}
+
+	if c.monitorPings {
+		// We call Ping on the driver shortly to verify startup assertions by
+		// driving internal behaviour of the sql standard library. We don't
+		// want this call to ping to be monitored for expectation purposes so
+		// temporarily disable.
+		c.mu.Lock()
+		c.monitorPings = false
+		c.mu.Unlock()
+		defer func() {
+			c.mu.Lock()
+			c.monitorPings = true
+			c.mu.Unlock()
+		}()
+	}
+	return db, c, db.Ping()
+}
+
+// configure applies the options and sets defaults for the ones omitted
+func (c *sqlmock) configure(options []func(*sqlmock) error) error {
 	for _, option := range options {
 		err := option(c)
 		if err != nil {
-			return db, c, err
+			return err
 		}
 	}
 	if c.converter == nil {
@@ -123,167 +212,342 @@ func (c *sqlmock) open(options []func(*sqlmock) error) (*sql.DB, Sqlmock, error)
 	if c.queryMatcher == nil {
 		c.queryMatcher = QueryMatcherRegexp
 	}
+	if c.cancelError == nil {
+		c.cancelError = defaultCancelError
+	}
+	if c.clock == nil {
+		c.clock = realClock{}
+	}
+	return nil
+}
 
-	if c.monitorPings {
-		// We call Ping on the driver shortly to verify startup assertions by
-		// driving internal behaviour of the sql standard library. We don't
-		// want this call to ping to be monitored for expectation purposes so
-		// temporarily disable.
-		c.monitorPings = false
-		defer func() { c.monitorPings = true }()
+// expect registers an expectation, it is safe to call
+// while the mock is used by other goroutines
+func (c *sqlmock) expect(e expectation) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e.common().declared = declaration(1)
+	e.common().mock = c
+	if err := c.validateSQL(e); err != nil {
+		e.common().invalid = err
+		if c.t != nil {
+			c.t.Error(err)
+		}
 	}
-	return db, c, db.Ping()
+
+	n, _ := c.queryMatcher.(normalizer)
+	c.expected = append(c.expected, e)
+	c.index.add(e, n)
 }
 
 func (c *sqlmock) ExpectClose() *ExpectedClose {
 	e := &ExpectedClose{}
-	c.expected = append(c.expected, e)
+	c.expect(e)
+	return e
+}
+
+func (c *sqlmock) ExpectConnect() *ExpectedConnect {
+	e := &ExpectedConnect{}
+	c.expect(e)
 	return e
 }
 
+func (c *sqlmock) connect() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	matched, next, _ := c.match(callConnect, "", nil)
+	if next != nil {
+		// connections are allowed, unless expected
+		next.Unlock()
+	}
+	if matched == nil {
+		return nil
+	}
+
+	expected := matched.(*ExpectedConnect)
+	expected.triggered = true
+	expected.Unlock()
+	// connections are not recorded in the journal, but they may
+	// meet the expectations someone waits for
+	c.notify()
+	return expected.err
+}
+
 func (c *sqlmock) MatchExpectationsInOrder(b bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	c.ordered = b
 }
 
 // Close a mock database driver connection. It may or may not
-// be called depending on the circumstances, but if it is called
-// there must be an *ExpectedClose expectation satisfied.
+// be called depending on the circumstances, database/sql closes
+// the connections of its pool as it trims them. Only when the
+// database is closed, there must be an *ExpectedClose expectation
+// satisfied, see closesConnector.
 // meets http://golang.org/pkg/database/sql/driver/#Conn interface
-func (c *sqlmock) Close() error {
+func (c *conn) Close() error {
 	c.drv.Lock()
-	defer c.drv.Unlock()
-
 	c.opened--
-	if c.opened == 0 {
+	if c.opened == 0 && c.drv.conns[c.dsn] == c.sqlmock {
 		delete(c.drv.conns, c.dsn)
 	}
+	last := c.opened == 0
+	c.drv.Unlock()
 
-	var expected *ExpectedClose
-	var fulfilled int
-	var ok bool
-	for _, next := range c.expected {
-		next.Lock()
-		if next.fulfilled() {
-			next.Unlock()
-			fulfilled++
-			continue
-		}
+	if c.isDiscarded() {
+		// the connection was reported as bad or broken and is discarded
+		// by database/sql, it is not the database being closed
+		return nil
+	}
+	if closesConnector || !last {
+		// only closing the last connection may be the database being
+		// closed, unless database/sql tells by closing the connector
+		return nil
+	}
+	return c.close()
+}
 
-		if expected, ok = next.(*ExpectedClose); ok {
-			break
-		}
+// close matches the database being closed against ExpectClose
+func (c *sqlmock) close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.ignoredKind(CallClose) {
+		return nil
+	}
 
+	matched, next, err := c.match(CallClose, "", nil)
+	if err != nil {
+		err = fmt.Errorf("call to database Close, %s", err)
+		if c.t != nil {
+			c.t.Error(err)
+		}
+		return c.reject(CallClose, "", nil, err)
+	}
+	if next != nil {
+		err = fmt.Errorf("call to database Close, was not expected, next expectation is: %s", next)
 		next.Unlock()
-		if c.ordered {
-			err := fmt.Errorf("call to database Close, was not expected, next expectation is: %s", next)
-			if c.t != nil {
-				c.t.Errorf(err.Error())
-			}
-			return err
+		if c.t != nil {
+			c.t.Error(err)
 		}
+		return c.reject(CallClose, "", nil, err)
 	}
-
-	if expected == nil {
+	if matched == nil {
 		msg := "call to database Close was not expected"
-		if fulfilled == len(c.expected) {
+		if c.allFulfilled() {
 			msg = "all expectations were already fulfilled, " + msg
 		}
 		if c.t != nil {
-			c.t.Errorf(msg)
+			c.t.Error(msg)
 		}
-		return fmt.Errorf(msg)
+		return c.reject(CallClose, "", nil, fmt.Errorf(msg))
 	}
 
+	expected := matched.(*ExpectedClose)
 	expected.triggered = true
+	c.record(CallClose, "", nil, CallMatched)
 	expected.Unlock()
 	return expected.err
 }
 
 func (c *sqlmock) ExpectationsWereMet() error {
-	for _, e := range c.expected {
-		e.Lock()
-		fulfilled := e.fulfilled()
-		e.Unlock()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.wereMet()
+}
 
-		if !fulfilled {
-			return fmt.Errorf("there is a remaining expectation which was not matched: %s", e)
-		}
+// wereMet checks the expectations and the rejected calls, c.mu must be held
+func (c *sqlmock) wereMet() error {
+	if err := c.pending(); err != nil {
+		return err
+	}
+	if !c.allowRejected {
+		return c.rejected()
+	}
+	return nil
+}
 
-		// for expected prepared statement check whether it was closed if expected
-		if prep, ok := e.(*ExpectedPrepare); ok {
-			if prep.mustBeClosed && !prep.wasClosed {
-				return fmt.Errorf("expected prepared statement to be closed, but it was not: %s", prep)
+// pending returns an error describing the first expectation,
+// which was not met yet, c.mu must be held
+func (c *sqlmock) pending() error {
+	if c.lintOnMet {
+		if warnings := c.lint(); len(warnings) > 0 {
+			msg := "there are lint warnings for the pending expectations:"
+			for _, w := range warnings {
+				msg += "\n  - " + w.String()
 			}
+			return errors.New(msg)
 		}
+	}
 
-		// must check whether all expected queried rows are closed
-		if query, ok := e.(*ExpectedQuery); ok {
-			if query.rowsMustBeClosed && !query.rowsWereClosed {
-				return fmt.Errorf("expected query rows to be closed, but it was not: %s", query)
-			}
+	for _, e := range c.expected {
+		if err := c.met(e); err != nil {
+			return err
+		}
+	}
+	return c.leaked()
+}
+
+// changed notifies the goroutines waiting for expectations to be
+// met, that the state of the mock changed, c.mu must not be held
+func (c *sqlmock) changed() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.notify()
+}
+
+// notify wakes up the goroutines waiting for a change, c.mu must be held
+func (c *sqlmock) notify() {
+	if c.waiting != nil {
+		close(c.waiting)
+		c.waiting = nil
+	}
+}
+
+// wait returns a channel, which is closed on the next change of
+// the state of the mock, c.mu must be held
+func (c *sqlmock) wait() <-chan struct{} {
+	if c.waiting == nil {
+		c.waiting = make(chan struct{})
+	}
+	return c.waiting
+}
+
+// met checks whether the expectation was met
+func (c *sqlmock) met(e expectation) error {
+	e.Lock()
+	defer e.Unlock()
+
+	if err := c.validate(e); err != nil {
+		return err
+	}
+
+	if !e.fulfilled() {
+		return fmt.Errorf("there is a remaining expectation which was not matched: %s", e)
+	}
+
+	// for expected prepared statement check whether it was closed if expected
+	if prep, ok := e.(*ExpectedPrepare); ok {
+		if prep.mustBeClosed && !prep.wasClosed {
+			return fmt.Errorf("expected prepared statement to be closed, but it was not: %s", prep)
+		}
+	}
+
+	// must check whether all expected queried rows are closed
+	if query, ok := e.(*ExpectedQuery); ok {
+		if query.rowsMustBeClosed && !query.rowsWereClosed {
+			return fmt.Errorf("expected query rows to be closed, but it was not: %s", query)
 		}
 	}
 	return nil
 }
 
+// delayFor returns the delay of a call, the delay set on the
+// expectation takes precedence over the latency of the mock,
+// even when it is zero
+func (c *sqlmock) delayFor(kind CallKind, delay *time.Duration) time.Duration {
+	if delay != nil {
+		return *delay
+	}
+	if c.latency == nil {
+		return 0
+	}
+	return c.latency.delay(kind)
+}
+
+// newTimer creates a timer on the clock of the mock, which
+// delays the given call and its SQL
+func (c *sqlmock) newTimer(d time.Duration, call, sql string) Timer {
+	if cc, ok := c.clock.(callClock); ok {
+		return cc.newCallTimer(d, call, sql)
+	}
+	return c.clock.NewTimer(d)
+}
+
+// sleep delays the given call and its SQL
+// for the duration on the clock of the mock
+func (c *sqlmock) sleep(d time.Duration, call, sql string) {
+	if d <= 0 {
+		return
+	}
+	<-c.newTimer(d, call, sql).C()
+}
+
 // Begin meets http://golang.org/pkg/database/sql/driver/#Conn interface
-func (c *sqlmock) Begin() (driver.Tx, error) {
+func (c *conn) Begin() (driver.Tx, error) {
+	if c.isBroken() {
+		return nil, driver.ErrBadConn
+	}
+	if err := c.checkBegin(); err != nil {
+		return nil, err
+	}
+	if err := c.injectFault(CallBegin); err != nil {
+		return nil, err
+	}
+
 	ex, err := c.begin()
+	c.discardIf(err)
 	if ex != nil {
-		time.Sleep(ex.delay)
+		c.breakIf(ex.breaks)
+		c.block(ex.release)
+		c.sleep(c.delayFor(CallBegin, ex.delay), "Begin", "")
 	}
 	if err != nil {
 		return nil, err
 	}
 
+	c.open(ex)
 	return c, nil
 }
 
 func (c *sqlmock) begin() (*ExpectedBegin, error) {
-	var expected *ExpectedBegin
-	var ok bool
-	var fulfilled int
-	for _, next := range c.expected {
-		next.Lock()
-		if next.fulfilled() {
-			next.Unlock()
-			fulfilled++
-			continue
-		}
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
-		if expected, ok = next.(*ExpectedBegin); ok {
-			break
-		}
+	if c.ignoredKind(CallBegin) {
+		return &ExpectedBegin{}, nil
+	}
 
+	matched, next, err := c.match(CallBegin, "", nil)
+	if err != nil {
+		err = fmt.Errorf("call to database transaction Begin, %s", err)
+		if c.t != nil {
+			c.t.Error(err)
+		}
+		return nil, c.reject(CallBegin, "", nil, err)
+	}
+	if next != nil {
+		err = fmt.Errorf("call to database transaction Begin, was not expected, next expectation is: %s", next)
 		next.Unlock()
-		if c.ordered {
-			err := fmt.Errorf("call to database transaction Begin, was not expected, next expectation is: %s", next)
-			if c.t != nil {
-				c.t.Errorf(err.Error())
-			}
-			return nil, err
+		if c.t != nil {
+			c.t.Error(err)
 		}
+		return nil, c.reject(CallBegin, "", nil, err)
 	}
-	if expected == nil {
+	if matched == nil {
 		msg := "call to database transaction Begin was not expected"
-		if fulfilled == len(c.expected) {
+		if c.allFulfilled() {
 			msg = "all expectations were already fulfilled, " + msg
 		}
 		if c.t != nil {
-			c.t.Errorf(msg)
+			c.t.Error(msg)
 		}
-		return nil, fmt.Errorf(msg)
+		return nil, c.reject(CallBegin, "", nil, fmt.Errorf(msg))
 	}
 
+	expected := matched.(*ExpectedBegin)
 	expected.triggered = true
+	expected.reach()
+	c.record(CallBegin, "", nil, CallMatched)
 	expected.Unlock()
-
 	return expected, expected.err
 }
 
 func (c *sqlmock) ExpectBegin() *ExpectedBegin {
 	e := &ExpectedBegin{}
-	c.expected = append(c.expected, e)
+	c.expect(e)
 	return e
 }
 
@@ -291,81 +555,102 @@ func (c *sqlmock) ExpectExec(expectedSQL string) *ExpectedExec {
 	e := &ExpectedExec{}
 	e.expectSQL = expectedSQL
 	e.converter = c.converter
-	c.expected = append(c.expected, e)
+	c.expect(e)
 	return e
 }
 
 // Prepare meets http://golang.org/pkg/database/sql/driver/#Conn interface
-func (c *sqlmock) Prepare(query string) (driver.Stmt, error) {
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	if c.isBroken() {
+		return nil, driver.ErrBadConn
+	}
+	if err := c.injectFault(CallPrepare); err != nil {
+		return nil, err
+	}
+
 	ex, err := c.prepare(query)
+	c.discardIf(err)
 	if ex != nil {
-		time.Sleep(ex.delay)
+		c.breakIf(ex.breaks)
+		c.block(ex.release)
+		c.sleep(c.delayFor(CallPrepare, ex.delay), "Prepare", query)
 	}
 	if err != nil {
 		return nil, err
 	}
 
-	return &statement{c, ex, query}, nil
+	return newStatement(c, ex, query), nil
 }
 
 func (c *sqlmock) prepare(query string) (*ExpectedPrepare, error) {
-	var expected *ExpectedPrepare
-	var fulfilled int
-	var ok bool
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.ignoredStatement(CallPrepare, query, nil); ok {
+		ex := &ExpectedPrepare{expectSQL: query}
+		ex.mock = c
+		ex.triggered = true
+		return ex, nil
+	}
 
-	for _, next := range c.expected {
-		next.Lock()
-		if next.fulfilled() {
-			next.Unlock()
-			fulfilled++
-			continue
+	matched, next, err := c.matchSQL(CallPrepare, query, func(e expectation) bool {
+		return c.matchQuery(e, query) == nil
+	})
+	if err != nil {
+		err = fmt.Errorf("call to Prepare statement with query '%s', %s", query, err)
+		if c.t != nil {
+			c.t.Error(err)
 		}
-
-		if c.ordered {
-			if expected, ok = next.(*ExpectedPrepare); ok {
-				break
-			}
-
+		return nil, c.reject(CallPrepare, query, nil, err)
+	}
+	if next != nil {
+		if stub := c.stubPrepare(query); stub != nil {
 			next.Unlock()
-			err := fmt.Errorf("call to Prepare statement with query '%s', was not expected, next expectation is: %s", query, next)
-			if c.t != nil {
-				c.t.Errorf(err.Error())
-			}
-			return nil, err
-		}
-
-		if pr, ok := next.(*ExpectedPrepare); ok {
-			if err := c.queryMatcher.Match(pr.expectSQL, query); err == nil {
-				expected = pr
-				break
-			}
+			return stub, nil
 		}
+		err = fmt.Errorf("call to Prepare statement with query '%s', was not expected, next expectation is: %s", query, next)
 		next.Unlock()
+		if c.t != nil {
+			c.t.Error(err)
+		}
+		return nil, c.reject(CallPrepare, query, nil, err)
 	}
-
-	if expected == nil {
+	if matched == nil {
+		if stub := c.stubPrepare(query); stub != nil {
+			return stub, nil
+		}
 		msg := "call to Prepare '%s' query was not expected"
-		if fulfilled == len(c.expected) {
+		if c.allFulfilled() {
 			msg = "all expectations were already fulfilled, " + msg
 		}
-		err := fmt.Errorf(msg, query)
+		err = fmt.Errorf(msg, query)
 		if c.t != nil {
-			c.t.Errorf(err.Error())
+			c.t.Error(err)
 		}
-		return nil, err
+		return nil, c.reject(CallPrepare, query, nil, err)
 	}
+
+	expected := matched.(*ExpectedPrepare)
 	defer expected.Unlock()
-	if err := c.queryMatcher.Match(expected.expectSQL, query); err != nil {
-		return nil, fmt.Errorf("Prepare: %v", err)
+	if expected.invalid != nil {
+		return nil, expected.invalid
+	}
+	if err := c.matchQuery(expected, query); err != nil {
+		if stub := c.stubPrepare(query); stub != nil {
+			return stub, nil
+		}
+		return nil, c.reject(CallPrepare, query, nil, fmt.Errorf("Prepare: %v", err))
 	}
 
 	expected.triggered = true
+	expected.reach()
+	c.record(CallPrepare, query, nil, CallMatched)
 	return expected, expected.err
 }
 
 func (c *sqlmock) ExpectPrepare(expectedSQL string) *ExpectedPrepare {
-	e := &ExpectedPrepare{expectSQL: expectedSQL, mock: c}
-	c.expected = append(c.expected, e)
+	e := &ExpectedPrepare{expectSQL: expectedSQL}
+	c.expect(e)
 	return e
 }
 
@@ -373,104 +658,153 @@ func (c *sqlmock) ExpectQuery(expectedSQL string) *ExpectedQuery {
 	e := &ExpectedQuery{}
 	e.expectSQL = expectedSQL
 	e.converter = c.converter
-	c.expected = append(c.expected, e)
+	c.expect(e)
 	return e
 }
 
 func (c *sqlmock) ExpectCommit() *ExpectedCommit {
 	e := &ExpectedCommit{}
-	c.expected = append(c.expected, e)
+	c.expect(e)
 	return e
 }
 
 func (c *sqlmock) ExpectRollback() *ExpectedRollback {
 	e := &ExpectedRollback{}
-	c.expected = append(c.expected, e)
+	c.expect(e)
 	return e
 }
 
 // Commit meets http://golang.org/pkg/database/sql/driver/#Tx
-func (c *sqlmock) Commit() error {
-	var expected *ExpectedCommit
-	var fulfilled int
-	var ok bool
-	for _, next := range c.expected {
-		next.Lock()
-		if next.fulfilled() {
-			next.Unlock()
-			fulfilled++
-			continue
-		}
+func (c *conn) Commit() error {
+	if err := c.finish(CallCommit, nil); err != nil {
+		return err
+	}
+	if c.isBroken() {
+		return driver.ErrBadConn
+	}
+	if err := c.injectFault(CallCommit); err != nil {
+		return err
+	}
 
-		if expected, ok = next.(*ExpectedCommit); ok {
-			break
-		}
+	ex, err := c.commit()
+	c.discardIf(err)
+	if ex != nil {
+		c.breakIf(ex.breaks)
+		c.block(ex.release)
+		c.sleep(c.delayFor(CallCommit, ex.delay), "Commit", "")
+	}
+	return err
+}
+
+func (c *sqlmock) commit() (*ExpectedCommit, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
+	if c.ignoredKind(CallCommit) {
+		return &ExpectedCommit{}, nil
+	}
+
+	matched, next, err := c.match(CallCommit, "", nil)
+	if err != nil {
+		err = fmt.Errorf("call to Commit transaction, %s", err)
+		if c.t != nil {
+			c.t.Error(err)
+		}
+		return nil, c.reject(CallCommit, "", nil, err)
+	}
+	if next != nil {
+		err = fmt.Errorf("call to Commit transaction, was not expected, next expectation is: %s", next)
 		next.Unlock()
-		if c.ordered {
-			err := fmt.Errorf("call to Commit transaction, was not expected, next expectation is: %s", next)
-			if c.t != nil {
-				c.t.Errorf(err.Error())
-			}
-			return err
+		if c.t != nil {
+			c.t.Error(err)
 		}
+		return nil, c.reject(CallCommit, "", nil, err)
 	}
-	if expected == nil {
+	if matched == nil {
 		msg := "call to Commit transaction was not expected"
-		if fulfilled == len(c.expected) {
+		if c.allFulfilled() {
 			msg = "all expectations were already fulfilled, " + msg
 		}
 		if c.t != nil {
-			c.t.Errorf(msg)
+			c.t.Error(msg)
 		}
-		return fmt.Errorf(msg)
+		return nil, c.reject(CallCommit, "", nil, fmt.Errorf(msg))
 	}
 
+	expected := matched.(*ExpectedCommit)
 	expected.triggered = true
+	expected.reach()
+	c.record(CallCommit, "", nil, CallMatched)
+	if r, ok := expected.next(); ok {
+		hit := expected.respond(r)
+		expected.Unlock()
+		return hit, hit.err
+	}
 	expected.Unlock()
-	return expected.err
+	return expected, expected.err
 }
 
 // Rollback meets http://golang.org/pkg/database/sql/driver/#Tx
-func (c *sqlmock) Rollback() error {
-	var expected *ExpectedRollback
-	var fulfilled int
-	var ok bool
-	for _, next := range c.expected {
-		next.Lock()
-		if next.fulfilled() {
-			next.Unlock()
-			fulfilled++
-			continue
-		}
+func (c *conn) Rollback() error {
+	if err := c.finish(CallRollback, nil); err != nil {
+		return err
+	}
+	if c.isBroken() {
+		return driver.ErrBadConn
+	}
+	if err := c.injectFault(CallRollback); err != nil {
+		return err
+	}
 
-		if expected, ok = next.(*ExpectedRollback); ok {
-			break
-		}
+	ex, err := c.rollback()
+	c.discardIf(err)
+	if ex != nil {
+		c.breakIf(ex.breaks)
+		c.sleep(c.delayFor(CallRollback, ex.delay), "Rollback", "")
+	}
+	return err
+}
 
+func (c *sqlmock) rollback() (*ExpectedRollback, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.ignoredKind(CallRollback) {
+		return &ExpectedRollback{}, nil
+	}
+
+	matched, next, err := c.match(CallRollback, "", nil)
+	if err != nil {
+		err = fmt.Errorf("call to Rollback transaction, %s", err)
+		if c.t != nil {
+			c.t.Error(err)
+		}
+		return nil, c.reject(CallRollback, "", nil, err)
+	}
+	if next != nil {
+		err = fmt.Errorf("call to Rollback transaction, was not expected, next expectation is: %s", next)
 		next.Unlock()
-		if c.ordered {
-			err := fmt.Errorf("call to Rollback transaction, was not expected, next expectation is: %s", next)
-			if c.t != nil {
-				c.t.Errorf(err.Error())
-			}
-			return err
+		if c.t != nil {
+			c.t.Error(err)
 		}
+		return nil, c.reject(CallRollback, "", nil, err)
 	}
-	if expected == nil {
+	if matched == nil {
 		msg := "call to Rollback transaction was not expected"
-		if fulfilled == len(c.expected) {
+		if c.allFulfilled() {
 			msg = "all expectations were already fulfilled, " + msg
 		}
 		if c.t != nil {
-			c.t.Errorf(msg)
+			c.t.Error(msg)
 		}
-		return fmt.Errorf(msg)
+		return nil, c.reject(CallRollback, "", nil, fmt.Errorf(msg))
 	}
 
+	expected := matched.(*ExpectedRollback)
 	expected.triggered = true
+	c.record(CallRollback, "", nil, CallMatched)
 	expected.Unlock()
-	return expected.err
+	return expected, expected.err
 }
 
 // NewRows allows Rows to be created from a
@@ -483,5 +817,7 @@ func (c *sqlmock) NewRows(columns []string) *Rows {
 }
 
 func (c *sqlmock) FailAndReturnError(t *testing.T) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	c.t = t
 }
\ No newline at end of file
@@ -21,13 +21,26 @@ type namedValue struct {
 	Value   driver.Value
 }
 
+// defaultCancelError is never used before Go 1.8, since
+// there is no context, which may cancel a call
+func defaultCancelError(ctxErr error) error {
+	return ctxErr
+}
+
 func (c *sqlmock) ExpectPing() *ExpectedPing {
 	log.Println("ExpectPing has no effect on Go 1.7 or below")
 	return &ExpectedPing{}
 }
 
 // Query meets http://golang.org/pkg/database/sql/driver/#Queryer
-func (c *sqlmock) Query(query string, args []driver.Value) (driver.Rows, error) {
+func (c *conn) Query(query string, args []driver.Value) (driver.Rows, error) {
+	if c.isBroken() {
+		return nil, driver.ErrBadConn
+	}
+	if err := c.injectFault(CallQuery); err != nil {
+		return nil, err
+	}
+
 	namedArgs := make([]namedValue, len(args))
 	for i, v := range args {
 		namedArgs[i] = namedValue{
@@ -37,79 +50,133 @@ func (c *sqlmock) Query(query string, args []driver.Value) (driver.Rows, error)
 	}
 
 	ex, err := c.query(query, namedArgs)
+	c.discardIf(err)
 	if ex != nil {
-		time.Sleep(ex.delay)
+		c.breakIf(ex.breaks)
+		c.block(ex.release)
+		c.sleep(c.delayFor(CallQuery, ex.delay), "Query", query)
 	}
 	if err != nil {
 		return nil, err
 	}
 
+	if rs, ok := ex.rows.(nextBinder); ok {
+		rs.bindBeforeNext(func(delay time.Duration) error {
+			c.sleep(delay, "Rows.Next", query)
+			return c.injectRowFault()
+		})
+	}
 	return ex.rows, nil
 }
 
 func (c *sqlmock) query(query string, args []namedValue) (*ExpectedQuery, error) {
-	var expected *ExpectedQuery
-	var fulfilled int
-	var ok bool
-	for _, next := range c.expected {
-		next.Lock()
-		if next.fulfilled() {
-			next.Unlock()
-			fulfilled++
-			continue
-		}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if rule, ok := c.ignoredStatement(CallQuery, query, values(args)); ok {
+		ex := &ExpectedQuery{}
+		ex.expectSQL = query
+		ex.triggered = true
+		ex.rows = rule.answerRows(ex)
+		return ex, nil
+	}
 
-		if c.ordered {
-			if expected, ok = next.(*ExpectedQuery); ok {
-				break
-			}
+	matched, next, err := c.matchSQL(CallQuery, query, func(e expectation) bool {
+		qr := e.(*ExpectedQuery)
+		if err := c.matchQuery(qr, query); err != nil {
+			return false
+		}
+		return qr.attemptArgMatch(args) == nil
+	})
+	if err != nil {
+		err = fmt.Errorf("call to Query '%s' with args %+v, %s", query, args, err)
+		if c.t != nil {
+			c.t.Error(err)
+		}
+		return nil, c.reject(CallQuery, query, values(args), err)
+	}
+	if next != nil {
+		if stub, err := c.stubQuery(query, args); stub != nil || err != nil {
 			next.Unlock()
-			return nil, fmt.Errorf("call to Query '%s' with args %+v, was not expected, next expectation is: %s", query, args, next)
-		}
-		if qr, ok := next.(*ExpectedQuery); ok {
-			if err := c.queryMatcher.Match(qr.expectSQL, query); err != nil {
-				next.Unlock()
-				continue
-			}
-			if err := qr.attemptArgMatch(args); err == nil {
-				expected = qr
-				break
-			}
+			return stub, err
 		}
+		err = fmt.Errorf("call to Query '%s' with args %+v, was not expected, next expectation is: %s", query, args, next)
 		next.Unlock()
+		if c.t != nil {
+			c.t.Error(err)
+		}
+		return nil, c.reject(CallQuery, query, values(args), err)
 	}
-
-	if expected == nil {
+	if matched == nil {
+		if stub, err := c.stubQuery(query, args); stub != nil || err != nil {
+			return stub, err
+		}
 		msg := "call to Query '%s' with args %+v was not expected"
-		if fulfilled == len(c.expected) {
+		if c.allFulfilled() {
 			msg = "all expectations were already fulfilled, " + msg
 		}
-		return nil, fmt.Errorf(msg, query, args)
+		err = fmt.Errorf(msg, query, args)
+		if c.t != nil {
+			c.t.Error(err)
+		}
+		return nil, c.reject(CallQuery, query, values(args), err)
 	}
 
+	expected := matched.(*ExpectedQuery)
 	defer expected.Unlock()
+	if expected.invalid != nil {
+		return nil, expected.invalid
+	}
 
-	if err := c.queryMatcher.Match(expected.expectSQL, query); err != nil {
-		return nil, fmt.Errorf("Query: %v", err)
+	if err := c.matchQuery(expected, query); err != nil {
+		if stub, err := c.stubQuery(query, args); stub != nil || err != nil {
+			return stub, err
+		}
+		if c.t != nil {
+			c.t.Errorf("Query: %v", err)
+		}
+		return nil, c.reject(CallQuery, query, values(args), fmt.Errorf("Query: %v", err))
 	}
 
 	if err := expected.argsMatches(args); err != nil {
-		return nil, fmt.Errorf("Query '%s', arguments do not match: %s", query, err)
+		if stub, err := c.stubQuery(query, args); stub != nil || err != nil {
+			return stub, err
+		}
+		if c.t != nil {
+			c.t.Errorf("Query '%s', arguments do not match: %s", query, err)
+		}
+		return nil, c.reject(CallQuery, query, values(args), fmt.Errorf("Query '%s', arguments do not match: %s", query, err))
 	}
 
 	expected.triggered = true
+	expected.reach()
+	c.record(CallQuery, query, values(args), CallMatched)
+	if r, ok := expected.next(); ok {
+		return expected.respond(r)
+	}
 	if expected.err != nil {
 		return expected, expected.err // mocked to return error
 	}
 
 	if expected.rows == nil {
-		return nil, fmt.Errorf("Query '%s' with args %+v, must return a database/sql/driver.Rows, but it was not set for expectation %T as %+v", query, args, expected, expected)
+		err := fmt.Errorf("Query '%s' with args %+v, must return a database/sql/driver.Rows, but it was not set for expectation %T as %+v", query, args, expected, expected)
+		if c.t != nil {
+			c.t.Errorf("Query: %v", err)
+		}
+		return nil, err
 	}
 	return expected, nil
 }
 
 // Exec meets http://golang.org/pkg/database/sql/driver/#Execer
-func (c *sqlmock) Exec(query string, args []driver.Value) (driver.Result, error) {
+func (c *conn) Exec(query string, args []driver.Value) (driver.Result, error) {
+	if c.isBroken() {
+		return nil, driver.ErrBadConn
+	}
+	if err := c.injectFault(CallExec); err != nil {
+		return nil, err
+	}
+
 	namedArgs := make([]namedValue, len(args))
 	for i, v := range args {
 		namedArgs[i] = namedValue{
@@ -119,72 +186,113 @@ func (c *sqlmock) Exec(query string, args []driver.Value) (driver.Result, error)
 	}
 
 	ex, err := c.exec(query, namedArgs)
+	c.discardIf(err)
 	if ex != nil {
-		time.Sleep(ex.delay)
+		c.breakIf(ex.breaks)
+		c.block(ex.release)
+		c.sleep(c.delayFor(CallExec, ex.delay), "Exec", query)
 	}
 	if err != nil {
 		return nil, err
 	}
 
-	return ex.result, nil
+	return c.wrapResult(ex.result), nil
 }
 
 func (c *sqlmock) exec(query string, args []namedValue) (*ExpectedExec, error) {
-	var expected *ExpectedExec
-	var fulfilled int
-	var ok bool
-	for _, next := range c.expected {
-		next.Lock()
-		if next.fulfilled() {
-			next.Unlock()
-			fulfilled++
-			continue
-		}
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
-		if c.ordered {
-			if expected, ok = next.(*ExpectedExec); ok {
-				break
-			}
-			next.Unlock()
-			return nil, fmt.Errorf("call to ExecQuery '%s' with args %+v, was not expected, next expectation is: %s", query, args, next)
-		}
-		if exec, ok := next.(*ExpectedExec); ok {
-			if err := c.queryMatcher.Match(exec.expectSQL, query); err != nil {
-				next.Unlock()
-				continue
-			}
+	if rule, ok := c.ignoredStatement(CallExec, query, values(args)); ok {
+		ex := &ExpectedExec{result: rule.answerResult()}
+		ex.expectSQL = query
+		ex.triggered = true
+		return ex, nil
+	}
 
-			if err := exec.attemptArgMatch(args); err == nil {
-				expected = exec
-				break
-			}
+	matched, next, err := c.matchSQL(CallExec, query, func(e expectation) bool {
+		exec := e.(*ExpectedExec)
+		if err := c.matchQuery(exec, query); err != nil {
+			return false
+		}
+		return exec.attemptArgMatch(args) == nil
+	})
+	if err != nil {
+		err = fmt.Errorf("call to ExecQuery '%s' with args %+v, %s", query, args, err)
+		if c.t != nil {
+			c.t.Error(err)
+		}
+		return nil, c.reject(CallExec, query, values(args), err)
+	}
+	if next != nil {
+		if stub, err := c.stubExec(query, args); stub != nil || err != nil {
+			next.Unlock()
+			return stub, err
 		}
+		err = fmt.Errorf("call to ExecQuery '%s' with args %+v, was not expected, next expectation is: %s", query, args, next)
 		next.Unlock()
+		if c.t != nil {
+			c.t.Error(err)
+		}
+		return nil, c.reject(CallExec, query, values(args), err)
 	}
-	if expected == nil {
+	if matched == nil {
+		if stub, err := c.stubExec(query, args); stub != nil || err != nil {
+			return stub, err
+		}
 		msg := "call to ExecQuery '%s' with args %+v was not expected"
-		if fulfilled == len(c.expected) {
+		if c.allFulfilled() {
 			msg = "all expectations were already fulfilled, " + msg
 		}
-		return nil, fmt.Errorf(msg, query, args)
+		err = fmt.Errorf(msg, query, args)
+		if c.t != nil {
+			c.t.Error(err)
+		}
+		return nil, c.reject(CallExec, query, values(args), err)
 	}
+
+	expected := matched.(*ExpectedExec)
 	defer expected.Unlock()
+	if expected.invalid != nil {
+		return nil, expected.invalid
+	}
 
-	if err := c.queryMatcher.Match(expected.expectSQL, query); err != nil {
-		return nil, fmt.Errorf("ExecQuery: %v", err)
+	if err := c.matchQuery(expected, query); err != nil {
+		if stub, err := c.stubExec(query, args); stub != nil || err != nil {
+			return stub, err
+		}
+		if c.t != nil {
+			c.t.Errorf("ExecQuery: %v", err)
+		}
+		return nil, c.reject(CallExec, query, values(args), fmt.Errorf("ExecQuery: %v", err))
 	}
 
 	if err := expected.argsMatches(args); err != nil {
-		return nil, fmt.Errorf("ExecQuery '%s', arguments do not match: %s", query, err)
+		if stub, err := c.stubExec(query, args); stub != nil || err != nil {
+			return stub, err
+		}
+		if c.t != nil {
+			c.t.Errorf("ExecQuery '%s', arguments do not match: %s", query, err)
+		}
+		return nil, c.reject(CallExec, query, values(args), fmt.Errorf("ExecQuery '%s', arguments do not match: %s", query, err))
 	}
 
 	expected.triggered = true
+	expected.reach()
+	c.record(CallExec, query, values(args), CallMatched)
+	if r, ok := expected.next(); ok {
+		return expected.respond(r)
+	}
 	if expected.err != nil {
 		return expected, expected.err // mocked to return error
 	}
 
 	if expected.result == nil {
-		return nil, fmt.Errorf("ExecQuery '%s' with args %+v, must return a database/sql/driver.Result, but it was not set for expectation %T as %+v", query, args, expected, expected)
+		err := fmt.Errorf("ExecQuery '%s' with args %+v, must return a database/sql/driver.Result, but it was not set for expectation %T as %+v", query, args, expected, expected)
+		if c.t != nil {
+			c.t.Errorf("Query: %v", err)
+		}
+		return nil, err
 	}
 
 	return expected, nil
@@ -16,174 +16,429 @@ type Sqlmock interface {
 	// Embed common methods
 	SqlmockCommon
 
+	// WaitExpectationsWereMet blocks until all queued expectations were
+	// met, as ExpectationsWereMet reports, or the context is done, in which
+	// case the error of ExpectationsWereMet is returned. It returns as soon
+	// as a call is rejected, unless rejected calls are allowed. It is meant for
+	// code under test which issues calls from background goroutines.
+	WaitExpectationsWereMet(ctx context.Context) error
+
 	// NewRowsWithColumnDefinition allows Rows to be created from a
 	// sql driver.Value slice with a definition of sql metadata
 	NewRowsWithColumnDefinition(columns ...*Column) *Rows
 
 	// New Column allows to create a Column
 	NewColumn(name string) *Column
+
+	// ExpectResetSession expects the session of a pooled connection to
+	// be reset, which database/sql does before the connection is reused.
+	// the *ExpectedResetSession allows to mock the response, for example
+	// driver.ErrBadConn makes database/sql discard the connection.
+	//
+	// Session resets are not required to be expected, a reset which does
+	// not match the next pending ExpectedResetSession succeeds.
+	ExpectResetSession() *ExpectedResetSession
+
+	// ExpectIsValid expects the connection to be validated, which
+	// database/sql does whenever the connection is returned to the pool.
+	// the *ExpectedIsValid allows to report the connection as invalid,
+	// so that database/sql discards it and opens a new one.
+	//
+	// Validations are not required to be expected, a validation which
+	// does not match the next pending ExpectedIsValid reports the
+	// connection as valid.
+	ExpectIsValid() *ExpectedIsValid
 }
 
 // ErrCancelled defines an error value, which can be expected in case of
-// such cancellation error.
+// such cancellation error. The default cancellation error of the mock
+// matches it with errors.Is and also wraps the context error, so both
+// errors.Is(err, ErrCancelled) and errors.Is(err, context.DeadlineExceeded)
+// may be used to check it.
 var ErrCancelled = errors.New("canceling query due to user request")
 
+// cancelError is the default cancellation error, it wraps the
+// context error and is not tied to the messages of any database
+type cancelError struct {
+	err error
+}
+
+func (e *cancelError) Error() string {
+	if e.err == context.DeadlineExceeded {
+		return "canceling query due to context deadline exceeded"
+	}
+	return ErrCancelled.Error()
+}
+
+func (e *cancelError) Unwrap() error {
+	return e.err
+}
+
+func (e *cancelError) Is(target error) bool {
+	return target == ErrCancelled
+}
+
+func defaultCancelError(ctxErr error) error {
+	return &cancelError{err: ctxErr}
+}
+
 // Implement the "QueryerContext" interface
-func (c *sqlmock) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+func (c *conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if c.isBroken() {
+		return nil, driver.ErrBadConn
+	}
+	if err := c.injectFault(CallQuery); err != nil {
+		return nil, err
+	}
+
 	ex, err := c.query(query, args)
+	c.discardIf(err)
 	if ex != nil {
-		select {
-		case <-time.After(ex.delay):
-			if err != nil {
-				return nil, err
-			}
-			return ex.rows, nil
-		case <-ctx.Done():
-			return nil, ErrCancelled
+		c.breakIf(ex.breaks)
+		if err := c.blockFor(ctx, ex.release, ex.cancelErr); err != nil {
+			return nil, err
+		}
+		if err := c.waitFor(ctx, c.delayFor(CallQuery, ex.delay), ex.cancelErr, "Query", query); err != nil {
+			return nil, err
+		}
+		if err != nil {
+			return nil, err
+		}
+		if rs, ok := ex.rows.(nextBinder); ok {
+			rs.bindBeforeNext(func(delay time.Duration) error {
+				if ctx.Err() != nil {
+					return c.cancelled(ctx, ex.cancelErr)
+				}
+				if err := c.waitFor(ctx, delay, ex.cancelErr, "Rows.Next", query); err != nil {
+					return err
+				}
+				return c.injectRowFault()
+			})
 		}
+		return ex.rows, nil
 	}
 
 	return nil, err
 }
 
 // Implement the "ExecerContext" interface
-func (c *sqlmock) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+func (c *conn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if c.isBroken() {
+		return nil, driver.ErrBadConn
+	}
+	if err := c.injectFault(CallExec); err != nil {
+		return nil, err
+	}
+
 	ex, err := c.exec(query, args)
+	c.discardIf(err)
 	if ex != nil {
-		select {
-		case <-time.After(ex.delay):
-			if err != nil {
-				return nil, err
-			}
-			return ex.result, nil
-		case <-ctx.Done():
-			return nil, ErrCancelled
+		c.breakIf(ex.breaks)
+		if err := c.blockFor(ctx, ex.release, ex.cancelErr); err != nil {
+			return nil, err
+		}
+		if err := c.waitFor(ctx, c.delayFor(CallExec, ex.delay), ex.cancelErr, "Exec", query); err != nil {
+			return nil, err
 		}
+		if err != nil {
+			return nil, err
+		}
+		return c.wrapResult(ex.result), nil
 	}
 
 	return nil, err
 }
 
 // Implement the "ConnBeginTx" interface
-func (c *sqlmock) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+func (c *conn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if c.isBroken() {
+		return nil, driver.ErrBadConn
+	}
+	if err := c.checkBegin(); err != nil {
+		return nil, err
+	}
+	if err := c.injectFault(CallBegin); err != nil {
+		return nil, err
+	}
+
 	ex, err := c.begin()
+	c.discardIf(err)
 	if ex != nil {
-		select {
-		case <-time.After(ex.delay):
-			if err != nil {
-				return nil, err
-			}
-			return c, nil
-		case <-ctx.Done():
-			return nil, ErrCancelled
+		c.breakIf(ex.breaks)
+		if err := c.blockFor(ctx, ex.release, ex.cancelErr); err != nil {
+			return nil, err
+		}
+		if err := c.waitFor(ctx, c.delayFor(CallBegin, ex.delay), ex.cancelErr, "Begin", ""); err != nil {
+			return nil, err
+		}
+		if err != nil {
+			return nil, err
 		}
+		return &transaction{conn: c, ctx: ctx, state: c.open(ex)}, nil
 	}
 
 	return nil, err
 }
 
 // Implement the "ConnPrepareContext" interface
-func (c *sqlmock) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+func (c *conn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	if c.isBroken() {
+		return nil, driver.ErrBadConn
+	}
+	if err := c.injectFault(CallPrepare); err != nil {
+		return nil, err
+	}
+
 	ex, err := c.prepare(query)
+	c.discardIf(err)
 	if ex != nil {
-		select {
-		case <-time.After(ex.delay):
-			if err != nil {
-				return nil, err
-			}
-			return &statement{c, ex, query}, nil
-		case <-ctx.Done():
-			return nil, ErrCancelled
+		c.breakIf(ex.breaks)
+		if err := c.blockFor(ctx, ex.release, ex.cancelErr); err != nil {
+			return nil, err
+		}
+		if err := c.waitFor(ctx, c.delayFor(CallPrepare, ex.delay), ex.cancelErr, "Prepare", query); err != nil {
+			return nil, err
 		}
+		if err != nil {
+			return nil, err
+		}
+		return newStatement(c, ex, query), nil
 	}
 
 	return nil, err
 }
 
 // Implement the "Pinger" interface - the explicit DB driver ping was only added to database/sql in Go 1.8
-func (c *sqlmock) Ping(ctx context.Context) error {
-	if !c.monitorPings {
+func (c *conn) Ping(ctx context.Context) error {
+	if c.isBroken() {
+		return driver.ErrBadConn
+	}
+	c.mu.Lock()
+	monitorPings := c.monitorPings
+	c.mu.Unlock()
+	if !monitorPings {
 		return nil
 	}
 
 	ex, err := c.ping()
+	c.discardIf(err)
 	if ex != nil {
-		select {
-		case <-ctx.Done():
-			return ErrCancelled
-		case <-time.After(ex.delay):
+		c.breakIf(ex.breaks)
+		if err := c.waitFor(ctx, c.delayFor(CallPing, ex.delay), ex.cancelErr, "Ping", ""); err != nil {
+			return err
 		}
 	}
 
 	return err
 }
 
+// waitFor delays the given call and its SQL. If the context is done
+// before the delay has passed, the cancellation error is returned.
+func (c *sqlmock) waitFor(ctx context.Context, delay time.Duration, cancelErr error, call, sql string) error {
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := c.newTimer(delay, call, sql)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C():
+		return nil
+	case <-ctx.Done():
+		return c.cancelled(ctx, cancelErr)
+	}
+}
+
+// blockFor blocks until the call is released. If the context is done
+// before, the cancellation error is returned.
+func (c *sqlmock) blockFor(ctx context.Context, release <-chan struct{}, cancelErr error) error {
+	if release == nil {
+		return nil
+	}
+
+	select {
+	case <-release:
+		return nil
+	case <-ctx.Done():
+		return c.cancelled(ctx, cancelErr)
+	}
+}
+
+// cancelled returns the error for a call which context is done, that is
+// cancelErr set on the expectation or the one built by CancelErrorFunc.
+func (c *sqlmock) cancelled(ctx context.Context, cancelErr error) error {
+	if cancelErr != nil {
+		return cancelErr
+	}
+	return c.cancelError(ctx.Err())
+}
+
 func (c *sqlmock) ping() (*ExpectedPing, error) {
-	var expected *ExpectedPing
-	var fulfilled int
-	var ok bool
-	for _, next := range c.expected {
-		next.Lock()
-		if next.fulfilled() {
-			next.Unlock()
-			fulfilled++
-			continue
-		}
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
-		if expected, ok = next.(*ExpectedPing); ok {
-			break
-		}
+	if c.ignoredKind(CallPing) {
+		return &ExpectedPing{}, nil
+	}
 
+	matched, next, err := c.match(CallPing, "", nil)
+	if err != nil {
+		err = fmt.Errorf("call to database Ping, %s", err)
+		if c.t != nil {
+			c.t.Error(err)
+		}
+		return nil, c.reject(CallPing, "", nil, err)
+	}
+	if next != nil {
+		err = fmt.Errorf("call to database Ping, was not expected, next expectation is: %s", next)
 		next.Unlock()
-		if c.ordered {
-			err := fmt.Errorf("call to database Ping, was not expected, next expectation is: %s", next)
-			if c.t != nil {
-				c.t.Errorf(err.Error())
-			}
-			return nil, err
+		if c.t != nil {
+			c.t.Error(err)
 		}
+		return nil, c.reject(CallPing, "", nil, err)
 	}
-
-	if expected == nil {
+	if matched == nil {
 		msg := "call to database Ping was not expected"
-		if fulfilled == len(c.expected) {
+		if c.allFulfilled() {
 			msg = "all expectations were already fulfilled, " + msg
 		}
-		err := fmt.Errorf(msg)
 		if c.t != nil {
-			c.t.Errorf(err.Error())
+			c.t.Error(msg)
 		}
-		return nil, err
+		return nil, c.reject(CallPing, "", nil, fmt.Errorf(msg))
+	}
+
+	expected := matched.(*ExpectedPing)
+	expected.triggered = true
+	c.record(CallPing, "", nil, CallMatched)
+	expected.Unlock()
+	return expected, expected.err
+}
+
+// Implement the "SessionResetter" interface
+func (c *conn) ResetSession(ctx context.Context) error {
+	if c.isBroken() {
+		return driver.ErrBadConn
+	}
+
+	ex, err := c.resetSession()
+	if ex != nil {
+		c.breakIf(ex.breaks)
+	}
+	c.discardIf(err)
+	return err
+}
+
+func (c *sqlmock) resetSession() (*ExpectedResetSession, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	matched, next, _ := c.match(callResetSession, "", nil)
+	if next != nil {
+		// session resets are allowed, unless expected
+		next.Unlock()
+	}
+	if matched == nil {
+		return nil, nil
 	}
 
+	expected := matched.(*ExpectedResetSession)
 	expected.triggered = true
 	expected.Unlock()
+	c.notify()
 	return expected, expected.err
 }
 
+// Implement the "Validator" interface
+func (c *conn) IsValid() bool {
+	if c.isBroken() {
+		return false
+	}
+
+	ex := c.isValid()
+	if ex == nil {
+		return true
+	}
+	c.breakIf(ex.breaks)
+	if !ex.valid {
+		c.discard()
+		return false
+	}
+	return true
+}
+
+func (c *sqlmock) isValid() *ExpectedIsValid {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	matched, next, _ := c.match(callIsValid, "", nil)
+	if next != nil {
+		// validations are allowed, unless expected
+		next.Unlock()
+	}
+	if matched == nil {
+		return nil
+	}
+
+	expected := matched.(*ExpectedIsValid)
+	expected.triggered = true
+	expected.Unlock()
+	c.notify()
+	return expected
+}
+
+func (c *sqlmock) ExpectResetSession() *ExpectedResetSession {
+	e := &ExpectedResetSession{}
+	c.expect(e)
+	return e
+}
+
+func (c *sqlmock) ExpectIsValid() *ExpectedIsValid {
+	e := &ExpectedIsValid{valid: true}
+	c.expect(e)
+	return e
+}
+
 // Implement the "StmtExecContext" interface
 func (stmt *statement) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	if err := stmt.checkTx(CallExec, values(args)); err != nil {
+		return nil, err
+	}
 	return stmt.conn.ExecContext(ctx, stmt.query, args)
 }
 
 // Implement the "StmtQueryContext" interface
 func (stmt *statement) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	if err := stmt.checkTx(CallQuery, values(args)); err != nil {
+		return nil, err
+	}
 	return stmt.conn.QueryContext(ctx, stmt.query, args)
 }
 
 func (c *sqlmock) ExpectPing() *ExpectedPing {
-	if !c.monitorPings {
+	c.mu.Lock()
+	monitorPings := c.monitorPings
+	c.mu.Unlock()
+	if !monitorPings {
 		log.Println("ExpectPing will have no effect as monitoring pings is disabled. Use MonitorPingsOption to enable.")
 		return nil
 	}
 	e := &ExpectedPing{}
-	c.expected = append(c.expected, e)
+	c.expect(e)
 	return e
 }
 
 // Query meets http://golang.org/pkg/database/sql/driver/#Queryer
 // Deprecated: Drivers should implement QueryerContext instead.
-func (c *sqlmock) Query(query string, args []driver.Value) (driver.Rows, error) {
+func (c *conn) Query(query string, args []driver.Value) (driver.Rows, error) {
+	if c.isBroken() {
+		return nil, driver.ErrBadConn
+	}
+	if err := c.injectFault(CallQuery); err != nil {
+		return nil, err
+	}
+
 	namedArgs := make([]driver.NamedValue, len(args))
 	for i, v := range args {
 		namedArgs[i] = driver.NamedValue{
@@ -193,81 +448,110 @@ func (c *sqlmock) Query(query string, args []driver.Value) (driver.Rows, error)
 	}
 
 	ex, err := c.query(query, namedArgs)
+	c.discardIf(err)
 	if ex != nil {
-		time.Sleep(ex.delay)
+		c.breakIf(ex.breaks)
+		c.block(ex.release)
+		c.sleep(c.delayFor(CallQuery, ex.delay), "Query", query)
 	}
 	if err != nil {
 		return nil, err
 	}
 
+	if rs, ok := ex.rows.(nextBinder); ok {
+		rs.bindBeforeNext(func(delay time.Duration) error {
+			c.sleep(delay, "Rows.Next", query)
+			return c.injectRowFault()
+		})
+	}
 	return ex.rows, nil
 }
 
 func (c *sqlmock) query(query string, args []driver.NamedValue) (*ExpectedQuery, error) {
-	var expected *ExpectedQuery
-	var fulfilled int
-	var ok bool
-	for _, next := range c.expected {
-		next.Lock()
-		if next.fulfilled() {
-			next.Unlock()
-			fulfilled++
-			continue
-		}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if rule, ok := c.ignoredStatement(CallQuery, query, values(args)); ok {
+		ex := &ExpectedQuery{}
+		ex.expectSQL = query
+		ex.triggered = true
+		ex.rows = rule.answerRows(ex)
+		return ex, nil
+	}
 
-		if c.ordered {
-			if expected, ok = next.(*ExpectedQuery); ok {
-				break
-			}
-			next.Unlock()
-			err := fmt.Errorf("call to Query '%s' with args %+v, was not expected, next expectation is: %s", query, args, next)
-			if c.t != nil {
-				c.t.Errorf(err.Error())
-			}
-			return nil, err
+	matched, next, err := c.matchSQL(CallQuery, query, func(e expectation) bool {
+		qr := e.(*ExpectedQuery)
+		if err := c.matchQuery(qr, query); err != nil {
+			return false
 		}
-		if qr, ok := next.(*ExpectedQuery); ok {
-			if err := c.queryMatcher.Match(qr.expectSQL, query); err != nil {
-				next.Unlock()
-				continue
-			}
-			if err := qr.attemptArgMatch(args); err == nil {
-				expected = qr
-				break
-			}
+		return qr.attemptArgMatch(args) == nil
+	})
+	if err != nil {
+		err = fmt.Errorf("call to Query '%s' with args %+v, %s", query, args, err)
+		if c.t != nil {
+			c.t.Error(err)
+		}
+		return nil, c.reject(CallQuery, query, values(args), err)
+	}
+	if next != nil {
+		if stub, err := c.stubQuery(query, args); stub != nil || err != nil {
+			next.Unlock()
+			return stub, err
 		}
+		err = fmt.Errorf("call to Query '%s' with args %+v, was not expected, next expectation is: %s", query, args, next)
 		next.Unlock()
+		if c.t != nil {
+			c.t.Error(err)
+		}
+		return nil, c.reject(CallQuery, query, values(args), err)
 	}
-
-	if expected == nil {
+	if matched == nil {
+		if stub, err := c.stubQuery(query, args); stub != nil || err != nil {
+			return stub, err
+		}
 		msg := "call to Query '%s' with args %+v was not expected"
-		if fulfilled == len(c.expected) {
+		if c.allFulfilled() {
 			msg = "all expectations were already fulfilled, " + msg
 		}
-		err := fmt.Errorf(msg, query, args)
+		err = fmt.Errorf(msg, query, args)
 		if c.t != nil {
-			c.t.Errorf(err.Error())
+			c.t.Error(err)
 		}
-		return nil, err
+		return nil, c.reject(CallQuery, query, values(args), err)
 	}
 
+	expected := matched.(*ExpectedQuery)
 	defer expected.Unlock()
+	if expected.invalid != nil {
+		return nil, expected.invalid
+	}
 
-	if err := c.queryMatcher.Match(expected.expectSQL, query); err != nil {
+	if err := c.matchQuery(expected, query); err != nil {
+		if stub, err := c.stubQuery(query, args); stub != nil || err != nil {
+			return stub, err
+		}
 		if c.t != nil {
 			c.t.Errorf("Query: %v", err)
 		}
-		return nil, fmt.Errorf("Query: %v", err)
+		return nil, c.reject(CallQuery, query, values(args), fmt.Errorf("Query: %v", err))
 	}
 
 	if err := expected.argsMatches(args); err != nil {
+		if stub, err := c.stubQuery(query, args); stub != nil || err != nil {
+			return stub, err
+		}
 		if c.t != nil {
 			c.t.Errorf("Query '%s', arguments do not match: %s", query, err)
 		}
-		return nil, fmt.Errorf("Query '%s', arguments do not match: %s", query, err)
+		return nil, c.reject(CallQuery, query, values(args), fmt.Errorf("Query '%s', arguments do not match: %s", query, err))
 	}
 
 	expected.triggered = true
+	expected.reach()
+	c.record(CallQuery, query, values(args), CallMatched)
+	if r, ok := expected.next(); ok {
+		return expected.respond(r)
+	}
 	if expected.err != nil {
 		return expected, expected.err // mocked to return error
 	}
@@ -284,7 +568,14 @@ func (c *sqlmock) query(query string, args []driver.NamedValue) (*ExpectedQuery,
 
 // Exec meets http://golang.org/pkg/database/sql/driver/#Execer
 // Deprecated: Drivers should implement ExecerContext instead.
-func (c *sqlmock) Exec(query string, args []driver.Value) (driver.Result, error) {
+func (c *conn) Exec(query string, args []driver.Value) (driver.Result, error) {
+	if c.isBroken() {
+		return nil, driver.ErrBadConn
+	}
+	if err := c.injectFault(CallExec); err != nil {
+		return nil, err
+	}
+
 	namedArgs := make([]driver.NamedValue, len(args))
 	for i, v := range args {
 		namedArgs[i] = driver.NamedValue{
@@ -294,82 +585,103 @@ func (c *sqlmock) Exec(query string, args []driver.Value) (driver.Result, error)
 	}
 
 	ex, err := c.exec(query, namedArgs)
+	c.discardIf(err)
 	if ex != nil {
-		time.Sleep(ex.delay)
+		c.breakIf(ex.breaks)
+		c.block(ex.release)
+		c.sleep(c.delayFor(CallExec, ex.delay), "Exec", query)
 	}
 	if err != nil {
 		return nil, err
 	}
 
-	return ex.result, nil
+	return c.wrapResult(ex.result), nil
 }
 
 func (c *sqlmock) exec(query string, args []driver.NamedValue) (*ExpectedExec, error) {
-	var expected *ExpectedExec
-	var fulfilled int
-	var ok bool
-	for _, next := range c.expected {
-		next.Lock()
-		if next.fulfilled() {
-			next.Unlock()
-			fulfilled++
-			continue
-		}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if rule, ok := c.ignoredStatement(CallExec, query, values(args)); ok {
+		ex := &ExpectedExec{result: rule.answerResult()}
+		ex.expectSQL = query
+		ex.triggered = true
+		return ex, nil
+	}
 
-		if c.ordered {
-			if expected, ok = next.(*ExpectedExec); ok {
-				break
-			}
-			next.Unlock()
-			err := fmt.Errorf("call to ExecQuery '%s' with args %+v, was not expected, next expectation is: %s", query, args, next)
-			if c.t != nil {
-				c.t.Errorf(err.Error())
-			}
-			return nil, err
+	matched, next, err := c.matchSQL(CallExec, query, func(e expectation) bool {
+		exec := e.(*ExpectedExec)
+		if err := c.matchQuery(exec, query); err != nil {
+			return false
 		}
-		if exec, ok := next.(*ExpectedExec); ok {
-			if err := c.queryMatcher.Match(exec.expectSQL, query); err != nil {
-				next.Unlock()
-				continue
-			}
-
-			if err := exec.attemptArgMatch(args); err == nil {
-				expected = exec
-				break
-			}
+		return exec.attemptArgMatch(args) == nil
+	})
+	if err != nil {
+		err = fmt.Errorf("call to ExecQuery '%s' with args %+v, %s", query, args, err)
+		if c.t != nil {
+			c.t.Error(err)
+		}
+		return nil, c.reject(CallExec, query, values(args), err)
+	}
+	if next != nil {
+		if stub, err := c.stubExec(query, args); stub != nil || err != nil {
+			next.Unlock()
+			return stub, err
 		}
+		err = fmt.Errorf("call to ExecQuery '%s' with args %+v, was not expected, next expectation is: %s", query, args, next)
 		next.Unlock()
+		if c.t != nil {
+			c.t.Error(err)
+		}
+		return nil, c.reject(CallExec, query, values(args), err)
 	}
-	if expected == nil {
+	if matched == nil {
+		if stub, err := c.stubExec(query, args); stub != nil || err != nil {
+			return stub, err
+		}
 		msg := "call to ExecQuery '%s' with args %+v was not expected"
-		if fulfilled == len(c.expected) {
+		if c.allFulfilled() {
 			msg = "all expectations were already fulfilled, " + msg
 		}
-
-		err := fmt.Errorf(msg, query, args)
-
+		err = fmt.Errorf(msg, query, args)
 		if c.t != nil {
-			c.t.Errorf(err.Error())
+			c.t.Error(err)
 		}
-		return nil, err
+		return nil, c.reject(CallExec, query, values(args), err)
 	}
+
+	expected := matched.(*ExpectedExec)
 	defer expected.Unlock()
+	if expected.invalid != nil {
+		return nil, expected.invalid
+	}
 
-	if err := c.queryMatcher.Match(expected.expectSQL, query); err != nil {
+	if err := c.matchQuery(expected, query); err != nil {
+		if stub, err := c.stubExec(query, args); stub != nil || err != nil {
+			return stub, err
+		}
 		if c.t != nil {
 			c.t.Errorf("ExecQuery: %v", err)
 		}
-		return nil, fmt.Errorf("ExecQuery: %v", err)
+		return nil, c.reject(CallExec, query, values(args), fmt.Errorf("ExecQuery: %v", err))
 	}
 
 	if err := expected.argsMatches(args); err != nil {
+		if stub, err := c.stubExec(query, args); stub != nil || err != nil {
+			return stub, err
+		}
 		if c.t != nil {
 			c.t.Errorf("ExecQuery '%s', arguments do not match: %s", query, err)
 		}
-		return nil, fmt.Errorf("ExecQuery '%s', arguments do not match: %s", query, err)
+		return nil, c.reject(CallExec, query, values(args), fmt.Errorf("ExecQuery '%s', arguments do not match: %s", query, err))
 	}
 
 	expected.triggered = true
+	expected.reach()
+	c.record(CallExec, query, values(args), CallMatched)
+	if r, ok := expected.next(); ok {
+		return expected.respond(r)
+	}
 	if expected.err != nil {
 		return expected, expected.err // mocked to return error
 	}
@@ -400,3 +712,26 @@ func (c *sqlmock) NewRowsWithColumnDefinition(columns ...*Column) *Rows {
 func (c *sqlmock) NewColumn(name string) *Column {
 	return NewColumn(name)
 }
+
+// WaitExpectationsWereMet meets Sqlmock interface
+func (c *sqlmock) WaitExpectationsWereMet(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for c.pending() != nil {
+		// a rejected call fails the expectations, whatever follows
+		if err := c.rejected(); err != nil && !c.allowRejected {
+			return err
+		}
+		changed := c.wait()
+		c.mu.Unlock()
+		select {
+		case <-changed:
+			c.mu.Lock()
+		case <-ctx.Done():
+			c.mu.Lock()
+			return c.wereMet()
+		}
+	}
+	return c.wereMet()
+}
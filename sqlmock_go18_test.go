@@ -5,7 +5,9 @@ package sqlmock
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
 	"errors"
+	"strings"
 	"testing"
 	"time"
 )
@@ -34,7 +36,7 @@ func TestContextExecCancel(t *testing.T) {
 		t.Error("error was expected, but there was none")
 	}
 
-	if err != ErrCancelled {
+	if !errors.Is(err, ErrCancelled) {
 		t.Errorf("was expecting cancel error, but got: %v", err)
 	}
 
@@ -78,7 +80,7 @@ func TestPreparedStatementContextExecCancel(t *testing.T) {
 		t.Error("error was expected, but there was none")
 	}
 
-	if err != ErrCancelled {
+	if !errors.Is(err, ErrCancelled) {
 		t.Errorf("was expecting cancel error, but got: %v", err)
 	}
 
@@ -117,7 +119,7 @@ func TestContextExecWithNamedArg(t *testing.T) {
 		t.Error("error was expected, but there was none")
 	}
 
-	if err != ErrCancelled {
+	if !errors.Is(err, ErrCancelled) {
 		t.Errorf("was expecting cancel error, but got: %v", err)
 	}
 
@@ -195,7 +197,7 @@ func TestContextQueryCancel(t *testing.T) {
 		t.Error("error was expected, but there was none")
 	}
 
-	if err != ErrCancelled {
+	if !errors.Is(err, ErrCancelled) {
 		t.Errorf("was expecting cancel error, but got: %v", err)
 	}
 
@@ -242,7 +244,7 @@ func TestPreparedStatementContextQueryCancel(t *testing.T) {
 		t.Error("error was expected, but there was none")
 	}
 
-	if err != ErrCancelled {
+	if !errors.Is(err, ErrCancelled) {
 		t.Errorf("was expecting cancel error, but got: %v", err)
 	}
 
@@ -314,7 +316,7 @@ func TestContextBeginCancel(t *testing.T) {
 		t.Error("error was expected, but there was none")
 	}
 
-	if err != ErrCancelled {
+	if !errors.Is(err, ErrCancelled) {
 		t.Errorf("was expecting cancel error, but got: %v", err)
 	}
 
@@ -337,6 +339,7 @@ func TestContextBegin(t *testing.T) {
 	defer db.Close()
 
 	mock.ExpectBegin().WillDelayFor(time.Millisecond * 3)
+	mock.ExpectRollback()
 
 	ctx, cancel := context.WithCancel(context.Background())
 
@@ -351,7 +354,11 @@ func TestContextBegin(t *testing.T) {
 	}
 
 	if tx == nil {
-		t.Error("expected tx, but there was nil")
+		t.Fatal("expected tx, but there was nil")
+	}
+
+	if err := tx.Rollback(); err != nil {
+		t.Errorf("error was not expected on rollback, but got: %v", err)
 	}
 
 	if err := mock.ExpectationsWereMet(); err != nil {
@@ -381,7 +388,7 @@ func TestContextPrepareCancel(t *testing.T) {
 		t.Error("error was expected, but there was none")
 	}
 
-	if err != ErrCancelled {
+	if !errors.Is(err, ErrCancelled) {
 		t.Errorf("was expecting cancel error, but got: %v", err)
 	}
 
@@ -632,10 +639,455 @@ func TestPingExpectationsContextTimeout(t *testing.T) {
 
 	select {
 	case <-doneCh:
-		if err != ErrCancelled {
+		if !errors.Is(err, ErrCancelled) {
 			t.Errorf("expected error '%s' to be returned from Ping, but got '%s'", ErrCancelled, err)
 		}
 	case <-time.After(time.Second):
 		t.Errorf("expected Ping to return after context timeout, but it did not in a timely fashion")
 	}
 }
+
+func TestContextRowsCancelDuringIteration(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	rs := NewRows([]string{"id"}).AddRow(1).AddRow(2).AddRow(3).RowDelay(50 * time.Millisecond)
+	mock.ExpectQuery("SELECT id FROM reports").WillReturnRows(rs)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	rows, err := db.QueryContext(ctx, "SELECT id FROM reports")
+	if err != nil {
+		t.Fatalf("error was not expected, but got: %v", err)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		t.Fatalf("expected first row, but got error: %v", rows.Err())
+	}
+
+	go func() {
+		time.Sleep(time.Millisecond * 10)
+		cancel()
+	}()
+
+	start := time.Now()
+	for rows.Next() {
+	}
+	if time.Since(start) > time.Second {
+		t.Error("expected row iteration to be interrupted by cancelled context")
+	}
+
+	if err := rows.Err(); err == nil {
+		t.Error("error was expected, but there was none")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestContextRowsDelay(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	rs := NewRows([]string{"id"}).AddRow(1).AddRow(2).RowDelay(10 * time.Millisecond)
+	mock.ExpectQuery("SELECT id FROM reports").WillReturnRows(rs)
+
+	start := time.Now()
+	rows, err := db.QueryContext(context.Background(), "SELECT id FROM reports")
+	if err != nil {
+		t.Fatalf("error was not expected, but got: %v", err)
+	}
+	defer rows.Close()
+
+	var count int
+	for rows.Next() {
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		t.Errorf("error was not expected, but got: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("expected 2 rows, but got %d", count)
+	}
+	if time.Since(start) < 20*time.Millisecond {
+		t.Error("expected rows to be delayed")
+	}
+}
+
+func TestContextCommitCancel(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectCommit().WillDelayFor(time.Second)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatalf("error was not expected, but got: %v", err)
+	}
+
+	go func() {
+		time.Sleep(time.Millisecond * 10)
+		cancel()
+	}()
+
+	err = tx.Commit()
+	if !errors.Is(err, ErrCancelled) {
+		t.Errorf("was expecting cancel error, but got: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestContextRollbackDelay(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectRollback().WillDelayFor(20 * time.Millisecond)
+
+	tx, err := db.BeginTx(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("error was not expected, but got: %v", err)
+	}
+
+	start := time.Now()
+	if err := tx.Rollback(); err != nil {
+		t.Errorf("error was not expected, but got: %v", err)
+	}
+	if time.Since(start) < 20*time.Millisecond {
+		t.Error("expected rollback to be delayed")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestContextDeadlineExceededError(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectExec("DELETE FROM users").
+		WillDelayFor(time.Second).
+		WillReturnResult(NewResult(1, 1))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err = db.ExecContext(ctx, "DELETE FROM users")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("was expecting deadline exceeded error, but got: %v", err)
+	}
+
+	if !errors.Is(err, ErrCancelled) {
+		t.Errorf("was expecting cancel error, but got: %v", err)
+	}
+
+	if errors.Is(err, context.Canceled) {
+		t.Errorf("was not expecting context canceled error, but got: %v", err)
+	}
+
+	if err.Error() != "canceling query due to context deadline exceeded" {
+		t.Errorf("was expecting a message independent of the database, but got: %s", err)
+	}
+}
+
+func TestCancelErrorOption(t *testing.T) {
+	t.Parallel()
+	timeout := errors.New("statement timeout")
+	db, mock, err := New(CancelErrorOption(func(ctxErr error) error {
+		if ctxErr == context.DeadlineExceeded {
+			return timeout
+		}
+		return ctxErr
+	}))
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT id FROM users").
+		WillDelayFor(time.Second).
+		WillReturnRows(NewRows([]string{"id"}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err = db.QueryContext(ctx, "SELECT id FROM users")
+	if err != timeout {
+		t.Errorf("was expecting custom cancel error, but got: %v", err)
+	}
+}
+
+func TestExpectationCancelError(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	canceled := errors.New("pq: canceling statement due to user request")
+	mock.ExpectBegin().
+		WillDelayFor(time.Second).
+		WillReturnCancelError(canceled)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	go func() {
+		time.Sleep(time.Millisecond * 10)
+		cancel()
+	}()
+
+	_, err = db.BeginTx(ctx, nil)
+	if err != canceled {
+		t.Errorf("was expecting expectation cancel error, but got: %v", err)
+	}
+}
+
+func TestIsValidExpectationDiscardsConnection(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectExec("UPDATE users").WillReturnResult(NewResult(0, 1))
+	mock.ExpectIsValid().WillReturnValid(false)
+	mock.ExpectExec("UPDATE users").WillReturnResult(NewResult(0, 1))
+
+	if _, err := db.Exec("UPDATE users SET name = 'john'"); err != nil {
+		t.Fatalf("error was not expected, but got: %v", err)
+	}
+
+	if n := db.Stats().OpenConnections; n != 0 {
+		t.Errorf("expected invalid connection to be discarded, but there are %d open connections", n)
+	}
+
+	if _, err := db.Exec("UPDATE users SET name = 'jane'"); err != nil {
+		t.Fatalf("error was not expected on a new connection, but got: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestResetSessionExpectationBadConn(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectExec("UPDATE users").WillReturnResult(NewResult(0, 1))
+	mock.ExpectResetSession().WillReturnError(driver.ErrBadConn)
+	mock.ExpectExec("UPDATE users").WillReturnResult(NewResult(0, 1))
+
+	if _, err := db.Exec("UPDATE users SET name = 'john'"); err != nil {
+		t.Fatalf("error was not expected, but got: %v", err)
+	}
+
+	if _, err := db.Exec("UPDATE users SET name = 'jane'"); err != nil {
+		t.Fatalf("error was not expected after session reset failure, but got: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestSessionCallsAreNotRequired(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectExec("UPDATE users").WillReturnResult(NewResult(0, 1))
+	mock.ExpectExec("UPDATE users").WillReturnResult(NewResult(0, 1))
+
+	for i := 0; i < 2; i++ {
+		if _, err := db.Exec("UPDATE users SET name = 'john'"); err != nil {
+			t.Fatalf("error was not expected, but got: %v", err)
+		}
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestWaitExpectationsWereMet(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectExec("UPDATE jobs").WillReturnResult(NewResult(0, 1))
+	mock.ExpectQuery("SELECT id FROM jobs").WillReturnRows(NewRows([]string{"id"}).AddRow(1))
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		db.Exec("UPDATE jobs SET done = 1")
+		rows, err := db.Query("SELECT id FROM jobs")
+		if err != nil {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+		rows.Close()
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := mock.WaitExpectationsWereMet(ctx); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestWaitExpectationsWereMetTimeout(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err = mock.WaitExpectationsWereMet(ctx)
+	if err == nil {
+		t.Fatal("expected an error, begin was never called")
+	}
+	if err.Error() != mock.ExpectationsWereMet().Error() {
+		t.Errorf("expected the error of ExpectationsWereMet, but got: %s", err)
+	}
+}
+
+func TestWaitExpectationsWereMetReturnsOnRejectedCall(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectExec("UPDATE jobs").WillReturnResult(NewResult(0, 1))
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		db.Exec("DELETE FROM jobs")
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err = mock.WaitExpectationsWereMet(ctx)
+	if err == nil {
+		t.Fatal("expected an error, the delete was not expected")
+	}
+	if ctx.Err() != nil {
+		t.Errorf("expected to return on the rejected call before the context is done, but got: %s", err)
+	}
+	if !strings.Contains(err.Error(), "rejected") {
+		t.Errorf("expected the rejected call to be reported, but got: %s", err)
+	}
+}
+
+func TestWillBlockUntilParksQueryInFlight(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+	mock.MatchExpectationsInOrder(false)
+
+	release := make(chan struct{})
+	query := mock.ExpectQuery("SELECT balance").
+		WillBlockUntil(release).
+		WillReturnRows(NewRows([]string{"balance"}).AddRow(10))
+	mock.ExpectExec("UPDATE accounts").WillReturnResult(NewResult(0, 1))
+
+	done := make(chan error, 1)
+	go func() {
+		var balance int
+		done <- db.QueryRow("SELECT balance FROM accounts").Scan(&balance)
+	}()
+
+	<-query.Reached()
+	if _, err := db.Exec("UPDATE accounts SET balance = 0"); err != nil {
+		t.Errorf("unexpected error on update while the query is in flight: %s", err)
+	}
+	select {
+	case err := <-done:
+		t.Fatalf("expected the query to be blocked, but it returned: %v", err)
+	default:
+	}
+
+	close(release)
+	if err := <-done; err != nil {
+		t.Errorf("unexpected error on released query: %s", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestWillBlockUntilCancelledByContext(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	begin := mock.ExpectBegin().WillBlockUntil(make(chan struct{}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-begin.Reached()
+		cancel()
+	}()
+	if _, err := db.BeginTx(ctx, nil); err == nil {
+		t.Error("expected an error, the blocked begin was cancelled")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
@@ -1,16 +1,59 @@
 package sqlmock
 
+import (
+	"database/sql/driver"
+	"fmt"
+)
+
 type statement struct {
-	conn  *sqlmock
+	conn  *conn
 	ex    *ExpectedPrepare
 	query string
+	epoch int      // epoch of the expectation the statement was prepared for
+	tx    *txState // the transaction the statement was prepared in, if any
+}
+
+// newStatement returns the statement prepared for the expectation
+// in the transaction open on the connection, if there is one
+func newStatement(conn *conn, ex *ExpectedPrepare, query string) *statement {
+	conn.mu.Lock()
+	tx := conn.tx
+	conn.mu.Unlock()
+
+	ex.Lock()
+	defer ex.Unlock()
+	return &statement{conn: conn, ex: ex, query: query, epoch: ex.epoch, tx: tx}
+}
+
+// checkTx rejects a query or an exec of the statement, when
+// the transaction it was prepared in is done already
+func (stmt *statement) checkTx(kind CallKind, args []driver.Value) error {
+	c := stmt.conn
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if stmt.tx == nil || !stmt.tx.done {
+		return nil
+	}
+	err := txDoneError(fmt.Sprintf("call to %s statement '%s' prepared in a transaction %s", kind, stmt.query, stmt.tx.begun))
+	if c.t != nil {
+		c.t.Error(err)
+	}
+	return c.reject(kind, stmt.query, args, err)
 }
 
 func (stmt *statement) Close() error {
-	stmt.ex.wasClosed = true
-	return stmt.ex.closeErr
+	stmt.ex.Lock()
+	if stmt.epoch == stmt.ex.epoch {
+		stmt.ex.wasClosed = true
+	}
+	err := stmt.ex.closeErr
+	stmt.ex.Unlock()
+
+	stmt.conn.changed()
+	return err
 }
 
 func (stmt *statement) NumInput() int {
-	return -1
+	return stmt.conn.dialect.placeholders(stmt.query)
 }
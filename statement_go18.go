@@ -9,12 +9,12 @@ import (
 
 // Deprecated: Drivers should implement ExecerContext instead.
 func (stmt *statement) Exec(args []driver.Value) (driver.Result, error) {
-	return stmt.conn.ExecContext(context.Background(), stmt.query, convertValueToNamedValue(args))
+	return stmt.ExecContext(context.Background(), convertValueToNamedValue(args))
 }
 
 // Deprecated: Drivers should implement StmtQueryContext instead (or additionally).
 func (stmt *statement) Query(args []driver.Value) (driver.Rows, error) {
-	return stmt.conn.QueryContext(context.Background(), stmt.query, convertValueToNamedValue(args))
+	return stmt.QueryContext(context.Background(), convertValueToNamedValue(args))
 }
 
 func convertValueToNamedValue(args []driver.Value) []driver.NamedValue {
@@ -0,0 +1,64 @@
+package sqlmock
+
+// StubQuery registers a stub, which answers queries matching the SQL
+// when no expectation matches them. Stubs may be called any number of
+// times or not at all, they are never reported by ExpectationsWereMet.
+// The rows of the stub are returned afresh for every call. Statements
+// prepared for the SQL are accepted, unless an expectation matches.
+func (c *sqlmock) StubQuery(expectedSQL string) *ExpectedQuery {
+	e := &ExpectedQuery{}
+	e.expectSQL = expectedSQL
+	e.converter = c.converter
+	c.stub(e)
+	return e
+}
+
+// StubExec registers a stub, which answers execs matching the SQL
+// when no expectation matches them. Stubs may be called any number of
+// times or not at all, they are never reported by ExpectationsWereMet.
+// Statements prepared for the SQL are accepted, unless an expectation
+// matches.
+func (c *sqlmock) StubExec(expectedSQL string) *ExpectedExec {
+	e := &ExpectedExec{}
+	e.expectSQL = expectedSQL
+	e.converter = c.converter
+	c.stub(e)
+	return e
+}
+
+func (c *sqlmock) stub(e expectation) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e.common().declared = declaration(1)
+	if err := c.validateSQL(e); err != nil {
+		e.common().invalid = err
+		if c.t != nil {
+			c.t.Error(err)
+		}
+	}
+	c.stubs = append(c.stubs, e)
+}
+
+// stubPrepare accepts a statement, which no expectation matches, when
+// the SQL matches a stub, so that its queries and execs may be answered
+// by the stubs. c.mu must be held.
+func (c *sqlmock) stubPrepare(query string) *ExpectedPrepare {
+	for _, stub := range c.stubs {
+		stub.Lock()
+		declared, invalid := stub.common().declared, stub.common().invalid
+		matches := invalid == nil && c.matchQuery(stub, query) == nil
+		stub.Unlock()
+		if !matches {
+			continue
+		}
+
+		ex := &ExpectedPrepare{expectSQL: query}
+		ex.mock = c
+		ex.declared = declared
+		ex.triggered = true
+		c.record(CallPrepare, query, nil, CallStubbed)
+		return ex
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+// +build go1.8
+
+package sqlmock
+
+import (
+	"database/sql/driver"
+	"fmt"
+)
+
+// stubQuery answers a query, which no expectation matches, with the
+// first matching stub. The hit is a copy of the stub with its own rows.
+// c.mu must be held.
+func (c *sqlmock) stubQuery(query string, args []driver.NamedValue) (*ExpectedQuery, error) {
+	for _, e := range c.stubs {
+		stub, ok := e.(*ExpectedQuery)
+		if !ok {
+			continue
+		}
+
+		stub.Lock()
+		if stub.invalid != nil || c.matchQuery(stub, query) != nil || stub.attemptArgMatch(args) != nil {
+			stub.Unlock()
+			continue
+		}
+		hit := &ExpectedQuery{delay: stub.delay}
+		hit.expectSQL = stub.expectSQL
+		hit.args = stub.args
+		hit.err = stub.err
+		hit.cancelErr = stub.cancelErr
+		hit.declared = stub.declared
+		hit.breaks = stub.breaks
+		hit.triggered = true
+		if rows, ok := stub.rows.(rowsCloner); ok {
+			hit.rows = rows.cloneFor(hit)
+		}
+		stub.Unlock()
+
+		c.record(CallQuery, query, values(args), CallStubbed)
+		if hit.err == nil && hit.rows == nil {
+			return nil, fmt.Errorf("%s: StubQuery '%s' will return neither rows nor an error, use WillReturnRows or WillReturnError", hit.declared, hit.expectSQL)
+		}
+		return hit, hit.err
+	}
+	return nil, nil
+}
+
+// stubExec answers an exec, which no expectation matches, with the
+// first matching stub. c.mu must be held.
+func (c *sqlmock) stubExec(query string, args []driver.NamedValue) (*ExpectedExec, error) {
+	for _, e := range c.stubs {
+		stub, ok := e.(*ExpectedExec)
+		if !ok {
+			continue
+		}
+
+		stub.Lock()
+		if stub.invalid != nil || c.matchQuery(stub, query) != nil || stub.attemptArgMatch(args) != nil {
+			stub.Unlock()
+			continue
+		}
+		hit := &ExpectedExec{result: stub.result, delay: stub.delay}
+		hit.expectSQL = stub.expectSQL
+		hit.args = stub.args
+		hit.err = stub.err
+		hit.cancelErr = stub.cancelErr
+		hit.declared = stub.declared
+		hit.breaks = stub.breaks
+		hit.triggered = true
+		stub.Unlock()
+
+		c.record(CallExec, query, values(args), CallStubbed)
+		if hit.err == nil && hit.result == nil {
+			return nil, fmt.Errorf("%s: StubExec '%s' will return neither a result nor an error, use WillReturnResult or WillReturnError", hit.declared, hit.expectSQL)
+		}
+		return hit, hit.err
+	}
+	return nil, nil
+}
+
+// values returns the values of named arguments
+func values(args []driver.NamedValue) []driver.Value {
+	if args == nil {
+		return nil
+	}
+	vals := make([]driver.Value, len(args))
+	for i, arg := range args {
+		vals[i] = arg.Value
+	}
+	return vals
+}
@@ -0,0 +1,152 @@
+// +build go1.8
+
+package sqlmock
+
+import "testing"
+
+func TestStubQueryAnswersAnyNumberOfCalls(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.StubQuery("SELECT 1").WillReturnRows(NewRows([]string{"ok"}).AddRow(1))
+	mock.ExpectExec("UPDATE users").WillReturnResult(NewResult(0, 1))
+
+	for i := 0; i < 3; i++ {
+		var ok int
+		if err := db.QueryRow("SELECT 1").Scan(&ok); err != nil {
+			t.Fatalf("unexpected error on stubbed query %d: %s", i, err)
+		}
+		if ok != 1 {
+			t.Errorf("expected the stubbed row, but got: %d", ok)
+		}
+	}
+	if _, err := db.Exec("UPDATE users SET active = 1"); err != nil {
+		t.Errorf("unexpected error on update: %s", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+
+	calls := mock.Calls()
+	if len(calls) != 4 {
+		t.Fatalf("expected 4 calls in the journal, but got: %+v", calls)
+	}
+	for i, call := range calls[:3] {
+		if call.Kind != CallQuery || call.Outcome != CallStubbed || call.Query != "SELECT 1" {
+			t.Errorf("expected call %d to be a stubbed query, but got: %+v", i, call)
+		}
+	}
+	if calls[3].Kind != CallExec || calls[3].Outcome != CallMatched {
+		t.Errorf("expected a matched exec, but got: %+v", calls[3])
+	}
+}
+
+func TestStubIsConsultedOnlyWithoutMatchingExpectation(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.StubExec("INSERT INTO audit").WillReturnResult(NewResult(0, 1))
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO audit").WithArgs("login").WillReturnResult(NewResult(0, 2))
+	mock.ExpectCommit()
+
+	// in ordered mode, the stub answers while begin is the next expectation
+	if _, err := db.Exec("INSERT INTO audit VALUES (?)", "boot"); err != nil {
+		t.Fatalf("unexpected error on stubbed insert: %s", err)
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("unexpected error on begin: %s", err)
+	}
+	res, err := tx.Exec("INSERT INTO audit VALUES (?)", "login")
+	if err != nil {
+		t.Fatalf("unexpected error on insert: %s", err)
+	}
+	if affected, _ := res.RowsAffected(); affected != 2 {
+		t.Errorf("expected the expectation to take precedence over the stub, but got %d rows affected", affected)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Errorf("unexpected error on commit: %s", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestStubsAreNotRequired(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.StubQuery("SELECT value FROM flags").WillReturnRows(NewRows([]string{"value"}))
+	mock.StubExec("DELETE FROM sessions").WillReturnError(ErrCancelled)
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expected stubs not to be required, but got: %s", err)
+	}
+
+	if _, err := db.Exec("DELETE FROM sessions"); err != ErrCancelled {
+		t.Errorf("expected the stubbed error, but got: %v", err)
+	}
+	if _, err := db.Exec("DELETE FROM users"); err == nil {
+		t.Error("expected an error for a call neither expected nor stubbed")
+	}
+}
+
+func TestStubsAcceptPreparedStatements(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.StubQuery("SELECT 1").WillReturnRows(NewRows([]string{"ok"}).AddRow(1))
+	mock.StubExec("UPDATE users").WillReturnResult(NewResult(0, 1))
+
+	query, err := db.Prepare("SELECT 1")
+	if err != nil {
+		t.Fatalf("unexpected error on preparing a stubbed query: %s", err)
+	}
+	defer query.Close()
+	for i := 0; i < 2; i++ {
+		var ok int
+		if err := query.QueryRow().Scan(&ok); err != nil {
+			t.Fatalf("unexpected error on stubbed query %d: %s", i, err)
+		}
+	}
+
+	exec, err := db.Prepare("UPDATE users SET active = 1")
+	if err != nil {
+		t.Fatalf("unexpected error on preparing a stubbed exec: %s", err)
+	}
+	defer exec.Close()
+	if _, err := exec.Exec(); err != nil {
+		t.Errorf("unexpected error on stubbed exec: %s", err)
+	}
+
+	if _, err := db.Prepare("DELETE FROM users"); err == nil {
+		t.Error("expected an error on preparing a statement, which is neither expected nor stubbed")
+	}
+
+	calls := mock.Calls()
+	if len(calls) != 6 {
+		t.Fatalf("expected 6 calls in the journal, but got: %+v", calls)
+	}
+	if calls[0].Kind != CallPrepare || calls[0].Outcome != CallStubbed {
+		t.Errorf("expected a stubbed prepare, but got: %+v", calls[0])
+	}
+	if calls[5].Kind != CallPrepare || calls[5].Outcome != CallRejected {
+		t.Errorf("expected a rejected prepare, but got: %+v", calls[5])
+	}
+}
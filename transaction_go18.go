@@ -0,0 +1,66 @@
+// +build go1.8
+
+package sqlmock
+
+import (
+	"context"
+	"database/sql/driver"
+)
+
+// transaction is returned by BeginTx. It keeps the context the
+// transaction was started with, so that delayed Commit and Rollback
+// calls can be cancelled.
+type transaction struct {
+	conn  *conn
+	ctx   context.Context
+	state *txState
+}
+
+// Commit meets http://golang.org/pkg/database/sql/driver/#Tx
+func (tx *transaction) Commit() error {
+	if err := tx.conn.finish(CallCommit, tx.state); err != nil {
+		return err
+	}
+	if tx.conn.isBroken() {
+		return driver.ErrBadConn
+	}
+	if err := tx.conn.injectFault(CallCommit); err != nil {
+		return err
+	}
+
+	ex, err := tx.conn.commit()
+	tx.conn.discardIf(err)
+	if ex != nil {
+		tx.conn.breakIf(ex.breaks)
+		if err := tx.conn.blockFor(tx.ctx, ex.release, ex.cancelErr); err != nil {
+			return err
+		}
+		if err := tx.conn.waitFor(tx.ctx, tx.conn.delayFor(CallCommit, ex.delay), ex.cancelErr, "Commit", ""); err != nil {
+			return err
+		}
+	}
+	return err
+}
+
+// Rollback meets http://golang.org/pkg/database/sql/driver/#Tx
+func (tx *transaction) Rollback() error {
+	if err := tx.conn.finish(CallRollback, tx.state); err != nil {
+		return err
+	}
+	if tx.conn.isBroken() {
+		return driver.ErrBadConn
+	}
+	if err := tx.conn.injectFault(CallRollback); err != nil {
+		return err
+	}
+
+	ex, err := tx.conn.rollback()
+	tx.conn.discardIf(err)
+	if ex != nil {
+		tx.conn.breakIf(ex.breaks)
+		if err := tx.conn.waitFor(tx.ctx, tx.conn.delayFor(CallRollback, ex.delay), ex.cancelErr, "Rollback", ""); err != nil {
+			return err
+		}
+	}
+	return err
+}
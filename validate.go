@@ -0,0 +1,139 @@
+package sqlmock
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+)
+
+// sqlCompiler is implemented by query matchers, which compile the
+// SQL of an expectation, so it is compiled once when registered
+type sqlCompiler interface {
+	compile(expectedSQL string) (*regexp.Regexp, error)
+}
+
+// declaration returns the file and line of the caller, which
+// registered an expectation, skip is the number of frames to
+// skip above the function which calls declaration
+func declaration(skip int) string {
+	_, file, line, ok := runtime.Caller(skip + 2)
+	if !ok {
+		return "unknown"
+	}
+	return fmt.Sprintf("%s:%d", filepath.Base(file), line)
+}
+
+// validateSQL compiles the SQL of an expectation when it is registered,
+// so an invalid one fails where it is declared and not when matched
+func (c *sqlmock) validateSQL(e expectation) error {
+	v, ok := c.queryMatcher.(sqlCompiler)
+	if !ok {
+		return nil
+	}
+	sql, ok := expectedSQL(e)
+	if !ok {
+		return nil
+	}
+	re, err := v.compile(sql)
+	if err != nil {
+		return fmt.Errorf("%s: invalid sql of %T: %s", e.common().declared, e, err)
+	}
+	e.common().compiled = re
+	return nil
+}
+
+// matchQuery matches the query against the SQL of the expectation,
+// with its regexp compiled when registered. e must be locked.
+func (c *sqlmock) matchQuery(e expectation, query string) error {
+	if re := e.common().compiled; re != nil {
+		return matchRegexp(re, query)
+	}
+	sql, _ := expectedSQL(e)
+	return c.queryMatcher.Match(sql, query)
+}
+
+// validate checks whether a registered expectation is complete. Rows,
+// results and arguments are set after the expectation is registered,
+// so it is checked by ExpectationsWereMet and the error points at the
+// declaring line. The expectation must be locked.
+func (c *sqlmock) validate(e expectation) error {
+	if err := e.common().invalid; err != nil {
+		return err
+	}
+
+	switch e := e.(type) {
+	case *ExpectedQuery:
+		if e.rows == nil && e.err == nil && len(e.responses) == 0 {
+			return fmt.Errorf("%s: ExpectQuery '%s' will return neither rows nor an error, use WillReturnRows or WillReturnError", e.declared, e.expectSQL)
+		}
+		for i, r := range e.responses {
+			if r.Rows == nil && r.Err == nil && r.Delay <= 0 {
+				return fmt.Errorf("%s: response %d of ExpectQuery '%s' will return neither rows nor an error", e.declared, i+1, e.expectSQL)
+			}
+		}
+		return c.validateArgs(&e.queryBasedExpectation)
+	case *ExpectedExec:
+		if e.result == nil && e.err == nil && len(e.responses) == 0 {
+			return fmt.Errorf("%s: ExpectExec '%s' will return neither a result nor an error, use WillReturnResult or WillReturnError", e.declared, e.expectSQL)
+		}
+		for i, r := range e.responses {
+			if r.Result == nil && r.Err == nil && r.Delay <= 0 {
+				return fmt.Errorf("%s: response %d of ExpectExec '%s' will return neither a result nor an error", e.declared, i+1, e.expectSQL)
+			}
+		}
+		return c.validateArgs(&e.queryBasedExpectation)
+	}
+	return nil
+}
+
+// validateArgs checks the number of expected arguments against
+// the placeholders of the expected SQL, when the dialect is known
+func (c *sqlmock) validateArgs(e *queryBasedExpectation) error {
+	if e.args == nil {
+		return nil
+	}
+	sql, exact := literalSQL(c.queryMatcher, e.expectSQL)
+	if sql == "" {
+		return nil
+	}
+	n := c.dialect.placeholders(sql)
+	if n < 0 || (n == 0 && !exact) {
+		return nil
+	}
+	// a pattern may match only a part of the query, which may
+	// take more arguments than the pattern shows
+	if n > len(e.args) || (exact && n != len(e.args)) {
+		return fmt.Errorf("%s: '%s' takes %d arguments in %s dialect, but %d are expected", e.declared, e.expectSQL, n, c.dialect, len(e.args))
+	}
+	return nil
+}
+
+// literalSQL returns the expected SQL as plain text, in order to count
+// its placeholders. With the regexp matcher escaped characters are kept
+// and the operators are dropped, since the pattern may match only a part
+// of the query, it is not exact and may take more arguments. It returns an empty string for unknown
+// query matchers.
+func literalSQL(matcher QueryMatcher, expectSQL string) (sql string, exact bool) {
+	switch matcher.(type) {
+	case equalMatcher:
+		return expectSQL, true
+	case regexpMatcher:
+		var b bytes.Buffer
+		for i := 0; i < len(expectSQL); i++ {
+			switch ch := expectSQL[i]; {
+			case ch == '\\' && i+1 < len(expectSQL):
+				i++
+				b.WriteByte(expectSQL[i])
+			case strings.IndexByte("?$^*+|()[]{}.", ch) >= 0:
+				b.WriteByte(' ')
+			default:
+				b.WriteByte(ch)
+			}
+		}
+		return b.String(), false
+	}
+	return "", false
+}
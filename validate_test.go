@@ -0,0 +1,99 @@
+package sqlmock
+
+import (
+	"database/sql/driver"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestInvalidRegexpFailsAtDeclaration(t *testing.T) {
+	t.Parallel()
+	db, mock, err := New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT (").WillReturnRows(NewRows([]string{"id"}))
+
+	_, err = db.Query("SELECT (1)")
+	if err == nil || !strings.Contains(err.Error(), "invalid sql") {
+		t.Errorf("expected an invalid sql error, but got: %v", err)
+	}
+
+	err = mock.ExpectationsWereMet()
+	if err == nil {
+		t.Fatal("expected an error for invalid sql")
+	}
+	if !strings.HasPrefix(err.Error(), "validate_test.go:") || !strings.Contains(err.Error(), "missing closing )") {
+		t.Errorf("expected the error to point at the declaring line, but got: %s", err)
+	}
+}
+
+func TestIncompleteExpectations(t *testing.T) {
+	t.Parallel()
+	cases := map[string]func(Sqlmock){
+		"will return neither rows nor an error": func(mock Sqlmock) {
+			mock.ExpectQuery("SELECT name FROM users").WithArgs(1)
+		},
+		"will return neither a result nor an error": func(mock Sqlmock) {
+			mock.ExpectExec("DELETE FROM users")
+		},
+	}
+	for expected, register := range cases {
+		db, mock, err := New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		register(mock)
+
+		err = mock.ExpectationsWereMet()
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("expected an error which contains %q, but got: %v", expected, err)
+		} else if !strings.HasPrefix(err.Error(), "validate_test.go:") {
+			t.Errorf("expected the error to point at the declaring line, but got: %s", err)
+		}
+		db.Close()
+	}
+}
+
+func TestArgumentsCountAgainstDialect(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		dialect Dialect
+		matcher QueryMatcher
+		sql     string
+		args    []driver.Value
+		invalid bool
+	}{
+		{DialectPostgres, QueryMatcherRegexp, regexp.QuoteMeta("UPDATE users SET name = $1 WHERE id = $2"), []driver.Value{"john"}, true},
+		{DialectPostgres, QueryMatcherRegexp, regexp.QuoteMeta("UPDATE users SET name = $1 WHERE id = $2"), []driver.Value{"john", 1}, false},
+		{DialectMySQL, QueryMatcherRegexp, `UPDATE users SET name = \? WHERE id = \?`, []driver.Value{"john"}, true},
+		{DialectPostgres, QueryMatcherRegexp, `UPDATE users SET name = \$1`, []driver.Value{"john", 2}, false},
+		{DialectMySQL, QueryMatcherRegexp, "UPDATE users", []driver.Value{"john", 1}, false},
+		{DialectMySQL, QueryMatcherEqual, "UPDATE users SET name = ?", []driver.Value{"john", 1}, true},
+		{DialectMySQL, QueryMatcherEqual, "UPDATE users SET name = 'john'", []driver.Value{1}, true},
+		{DialectSQLServer, QueryMatcherEqual, "UPDATE users SET name = @p1", []driver.Value{"john"}, false},
+		{"", QueryMatcherEqual, "UPDATE users SET name = ?", []driver.Value{"john", 1}, false},
+	}
+	for _, c := range cases {
+		options := []func(*sqlmock) error{QueryMatcherOption(c.matcher)}
+		if c.dialect != "" {
+			options = append(options, DialectOption(c.dialect))
+		}
+		db, mock, err := New(options...)
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+
+		mock.ExpectExec(c.sql).WithArgs(c.args...).WillReturnResult(NewResult(0, 1))
+
+		err = mock.ExpectationsWereMet()
+		invalid := err != nil && strings.Contains(err.Error(), "arguments in")
+		if invalid != c.invalid {
+			t.Errorf("expected %q with %d arguments in %q dialect to be invalid: %t, but got: %v", c.sql, len(c.args), c.dialect, c.invalid, err)
+		}
+		db.Close()
+	}
+}